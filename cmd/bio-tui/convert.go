@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/fastq"
+)
+
+// Formats that convert and validate read as streams.
+const (
+	formatFASTA = "fasta"
+	formatFASTQ = "fastq"
+)
+
+// fastqExtensions are the usual extensions of FASTQ files.
+var fastqExtensions = []string{".fq", ".fastq"}
+
+// formatOf returns the format of a file: the one given, or else the one its
+// extension tells.
+func formatOf(path, given string) (string, error) {
+	switch given {
+	case formatFASTA, formatFASTQ:
+		return given, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown format %q: expected fasta or fastq", given)
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case slices.Contains(fastqExtensions, ext):
+		return formatFASTQ, nil
+	case slices.Contains(fasta.Format.Extensions, ext):
+		return formatFASTA, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s from its extension", path)
+}
+
+// sequence is a record read by convert, from either format. FASTA records
+// have no qualities.
+type sequence struct {
+	name string // Header line without its '>' or '@'
+	seq  []byte
+	qual []byte
+}
+
+// sequenceReader returns a function that reads the records of a FASTA or
+// FASTQ stream one at a time, returning io.EOF at the end.
+func sequenceReader(r io.Reader, format string) func() (sequence, error) {
+	if format == formatFASTQ {
+		p := fastq.NewParser(r)
+		return func() (sequence, error) {
+			rec, err := p.Next()
+			if err != nil {
+				return sequence{}, err
+			}
+			return sequence{name: rec.ID, seq: rec.Seq, qual: rec.Qual}, nil
+		}
+	}
+	p := fasta.NewParser(r)
+	return func() (sequence, error) {
+		rec, err := p.Next()
+		if err != nil {
+			return sequence{}, err
+		}
+		name := rec.ID
+		if rec.Description != "" {
+			name += " " + rec.Description
+		}
+		return sequence{name: name, seq: rec.Seq}, nil
+	}
+}
+
+// convert converts a FASTA or FASTQ file to either format.
+func convert(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("convert", "[options] <input>",
+		"Convert a FASTA or FASTQ file to FASTA or FASTQ, such as reads to FASTA\nor a FASTA file to another line width. The input format comes from its\nextension unless --from is given. Converted to FASTQ, FASTA bases all get\nthe quality of -q.", stderr)
+	from := fs.String("from", "", "input `format`: fasta or fastq (default from the extension)")
+	to := fs.String("to", formatFASTA, "output `format`: fasta or fastq")
+	output := fs.String("o", "", "write to `file` instead of stdout")
+	width := fs.Int("n", fasta.DefaultLineWidth, "wrap FASTA sequences every `bases`; 0 for one line each")
+	quality := fs.Int("q", 40, "Phred `quality` of FASTA bases converted to FASTQ")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return usageCode(err)
+	}
+	if len(files) != 1 {
+		return usageError(fs, fmt.Sprintf("expected one input file, got %d", len(files)))
+	}
+	input, err := formatOf(files[0], *from)
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+	if *to != formatFASTA && *to != formatFASTQ {
+		return usageError(fs, fmt.Sprintf("unknown --to format %q: expected fasta or fastq", *to))
+	}
+	if *width < 0 {
+		return usageError(fs, fmt.Sprintf("invalid line width %d", *width))
+	}
+	if *quality < 0 || *quality > 93 {
+		return usageError(fs, fmt.Sprintf("invalid quality %d: expected 0 to 93", *quality))
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening %v\n", err)
+		return exitError
+	}
+	defer f.Close()
+	w := stdout
+	if *output != "" {
+		out, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error creating %v\n", err)
+			return exitError
+		}
+		defer out.Close()
+		w = out
+	}
+	out := bufio.NewWriter(w)
+
+	next := sequenceReader(f, input)
+	for n := 1; ; n++ {
+		rec, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			out.Flush()
+			fmt.Fprintf(stderr, "Error in %s, record %d: %v\n", files[0], n, err)
+			return exitError
+		}
+		if *to == formatFASTA {
+			err = fasta.WriteRecord(out, rec.name, rec.seq, *width)
+		} else {
+			qual := rec.qual
+			if qual == nil {
+				qual = bytes.Repeat([]byte{byte(33 + *quality)}, len(rec.seq))
+			}
+			_, err = fmt.Fprintf(out, "@%s\n%s\n+\n%s\n", rec.name, rec.seq, qual)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error writing: %v\n", err)
+			return exitError
+		}
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "Error writing: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
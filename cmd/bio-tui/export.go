@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/export"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/remote"
+	"github.com/guillechuma/bio-tui/internal/ui"
+)
+
+// exportRegion writes a region of a FASTA file as the viewer shows it, as
+// a PNG figure or as JSON.
+func exportRegion(args []string, stdout, stderr io.Writer) int {
+	// 1. Parse the options, which may come before or after the file.
+	fs := newFlagSet("export", "--png|--json --region <region> [options] <fasta-file>",
+		"Export a region as the viewer shows it. With --png, draw it as a figure:\nits bases in the palette of the configuration, the ruler, the N-run lane and\nthe stats of the region. With --json, write its bases, stats and tracks for\nscripts and notebooks. Regions are \"name\" or \"name:start-end\", 1-based\nand inclusive.", stderr)
+	asPNG := fs.Bool("png", false, "draw the region as a PNG figure")
+	asJSON := fs.Bool("json", false, "write the region, its stats and tracks as JSON")
+	region := fs.String("r", "", "export `region`")
+	output := fs.String("o", "", "write to `file`, replacing it, or - for stdout (default a new <name>_<start>-<end>.png or .json, printed)")
+	columns := fs.Int("w", export.DefaultColumns, "draw `bases` per row")
+	indexPath := fs.String("index", "", "use the index in `file`, building it there if missing")
+	fs.StringVar(region, "region", "", "same as -r")
+	fs.StringVar(output, "output", "", "same as -o")
+	fs.IntVar(columns, "width", export.DefaultColumns, "same as -w")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return usageCode(err)
+	}
+	switch {
+	case !*asPNG && !*asJSON:
+		return usageError(fs, "no export format given: pass --png or --json")
+	case *asPNG && *asJSON:
+		return usageError(fs, "--png and --json cannot be used together")
+	case *region == "":
+		return usageError(fs, "no region given")
+	case len(positional) != 1:
+		return usageError(fs, fmt.Sprintf("expected one FASTA file, got %d", len(positional)))
+	case *columns <= 0:
+		return usageError(fs, fmt.Sprintf("invalid width %d", *columns))
+	}
+	cfg, ok := loadConfig(stderr)
+	if !ok {
+		return exitError
+	}
+
+	// 2. Read the region, with the stats and lanes the viewer would show.
+	path := positional[0]
+	reader, err := fasta.NewIndexedReader(adapter.OpenSpec{Path: path, Index: *indexPath, Notify: warnTo(stderr, "")})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening %s: %v\n", path, err)
+		return exitError
+	}
+	defer reader.Close()
+	reg, slice, err := readRegion(reader, *region)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading %s: %v\n", path, err)
+		return exitError
+	}
+	if *asJSON {
+		// Every track goes into JSON: the lanes shown are a display setting.
+		var buf bytes.Buffer
+		if err := export.WriteJSON(&buf, export.NewRegion(fileName(path), reg, slice)); err != nil {
+			fmt.Fprintf(stderr, "Error encoding %s: %v\n", reg, err)
+			return exitError
+		}
+		return writeExport(*output, outputName(reg, ".json"), buf.Bytes(), stdout, stderr)
+	}
+	var tracks []adapter.Track
+	for _, track := range slice.Tracks {
+		if track.Name != adapter.TrackSoftMask || cfg.Display.SoftMaskLane {
+			tracks = append(tracks, track)
+		}
+	}
+	slice.Tracks = tracks
+
+	// 3. Draw the figure.
+	figure := export.Figure{
+		Title:     fileName(path),
+		Region:    reg,
+		Slice:     slice,
+		Columns:   *columns,
+		Colors:    ui.BaseColors(cfg, fasta.InferSequenceType(slice.Sequence)),
+		Theme:     cfg.Theme,
+		Uppercase: cfg.Display.Uppercase,
+	}
+	var buf bytes.Buffer
+	if err := export.WritePNG(&buf, figure); err != nil {
+		fmt.Fprintf(stderr, "Error drawing %s: %v\n", reg, err)
+		return exitError
+	}
+	return writeExport(*output, outputName(reg, ".png"), buf.Bytes(), stdout, stderr)
+}
+
+// writeExport writes an export to path, or else to a new file named
+// fallback, reporting errors to stderr. An existing file is only replaced
+// when named with -o: the default name is numbered instead, and the path
+// written is printed.
+func writeExport(path, fallback string, data []byte, stdout, stderr io.Writer) int {
+	if path != "" {
+		if err := writeOutput(path, data, stdout); err != nil {
+			fmt.Fprintf(stderr, "Error writing %s: %v\n", path, err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	f, path, err := ui.CreateNew(fallback)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error creating %s: %v\n", fallback, err)
+		return exitError
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		fmt.Fprintf(stderr, "Error writing %s: %v\n", path, err)
+		return exitError
+	}
+	fmt.Fprintln(stdout, path)
+	return exitOK
+}
+
+// readRegion reads a region of a FASTA file, a whole sequence or part of
+// one, clipped to the sequence, with its stats and tracks.
+func readRegion(reader *fasta.IndexedReader, text string) (adapter.Region, adapter.Slice, error) {
+	r := parseFaidxRegion(text, reader)
+	if r.err != nil {
+		return adapter.Region{}, adapter.Slice{}, r.err
+	}
+	rec, ok := reader.Index[r.ref]
+	if !ok {
+		return adapter.Region{}, adapter.Slice{}, fmt.Errorf("sequence '%s' not found", r.ref)
+	}
+	if r.end < 0 || r.end > rec.Length {
+		r.end = rec.Length
+	}
+	if r.start >= r.end {
+		return adapter.Region{}, adapter.Slice{}, fmt.Errorf("region %s is empty", text)
+	}
+	var seq bytes.Buffer
+	if err := reader.FetchRange(r.ref, r.start, r.end, &seq); err != nil {
+		return adapter.Region{}, adapter.Slice{}, err
+	}
+	reg := adapter.Region{Ref: r.ref, Start: r.start + 1, End: r.end}
+	return reg, fasta.SliceOf(reg, seq.Bytes()), nil
+}
+
+// fileName returns the name of a file or URL, as shown above figures.
+func fileName(path string) string {
+	if remote.IsURL(path) {
+		return remote.Base(path)
+	}
+	return filepath.Base(path)
+}
+
+// outputName returns the default file name of an export: the region, with
+// the characters that file names do not allow replaced.
+func outputName(reg adapter.Region, ext string) string {
+	name := fmt.Sprintf("%s_%d-%d", reg.Ref, reg.Start, reg.End)
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	return name + ext
+}
+
+// writeOutput writes data to a file, or to stdout for "-".
+func writeOutput(path string, data []byte, stdout io.Writer) error {
+	if path == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExport_DefaultName(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir) // The default configuration
+	ref := filepath.Join(dir, "ref.fa")
+	if err := os.WriteFile(ref, []byte(faidxFasta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	if err := os.WriteFile("chr1_2-10.png", []byte("mine"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default name never replaces a file, and is printed once numbered.
+	for _, want := range []string{"chr1_2-10-2.png", "chr1_2-10-3.png"} {
+		var out, stderr bytes.Buffer
+		if status := run([]string{"export", "--png", "-r", "chr1:2-10", ref}, &out, &stderr); status != exitOK {
+			t.Fatalf("expected %d, got %d (stderr %q)", exitOK, status, stderr.String())
+		}
+		if got, want := out.String(), filepath.Join(dir, want)+"\n"; got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+		if data, err := os.ReadFile(want); err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+			t.Errorf("expected a figure in %s, got %v", want, err)
+		}
+	}
+	if data, _ := os.ReadFile("chr1_2-10.png"); string(data) != "mine" {
+		t.Errorf("expected the existing file to be kept, got %q", data)
+	}
+
+	// A file named with -o is replaced.
+	var out, stderr bytes.Buffer
+	if status := run([]string{"export", "--png", "-r", "chr1:2-10", "-o", "chr1_2-10.png", ref}, &out, &stderr); status != exitOK {
+		t.Fatalf("expected %d, got %d (stderr %q)", exitOK, status, stderr.String())
+	}
+	if data, _ := os.ReadFile("chr1_2-10.png"); !bytes.HasPrefix(data, []byte("\x89PNG")) || out.Len() != 0 {
+		t.Errorf("expected -o to replace the file silently, got %q and %q", data[:min(len(data), 8)], out.String())
+	}
+}
+
+func TestExport_DefaultNameJSON(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir) // The default configuration
+	ref := filepath.Join(dir, "ref.fa")
+	if err := os.WriteFile(ref, []byte(faidxFasta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	if err := os.WriteFile("chr2_3-4.json", []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	if status := run([]string{"export", "--json", "-r", "chr2:3-4", ref}, &out, &stderr); status != exitOK {
+		t.Fatalf("expected %d, got %d (stderr %q)", exitOK, status, stderr.String())
+	}
+	if got, want := out.String(), filepath.Join(dir, "chr2_3-4-2.json")+"\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if data, _ := os.ReadFile("chr2_3-4-2.json"); !bytes.Contains(data, []byte(`"region": "chr2:3-4"`)) {
+		t.Errorf("expected the region in chr2_3-4-2.json, got %q", data)
+	}
+	if data, _ := os.ReadFile("chr2_3-4.json"); string(data) != "{}" {
+		t.Errorf("expected the existing file to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bed"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+)
+
+// faidxRegion is a region to extract, with the name its record is given.
+type faidxRegion struct {
+	name     string // Header of the output record, the region as written
+	ref      string
+	start    int64 // 0-based
+	end      int64 // Exclusive; -1 for the end of the sequence
+	explicit bool  // The end was given, so a shorter sequence is truncated
+	err      error // Why the region could not be parsed
+}
+
+// faidx extracts regions of a FASTA file like samtools faidx: same options,
+// same messages and byte for byte the same output. Without regions, it
+// only builds the index. It returns the exit code.
+func faidx(args []string, stdout, stderr io.Writer) int {
+	// 1. Parse the options, which may come before or after the arguments.
+	fs := newFlagSet("faidx", "[options] <file.fa> [region1 [...]]",
+		"Extract regions of a FASTA file as FASTA, with the options and output of\nsamtools faidx. Regions are \"name\", \"name:start\", \"name:start-\" or\n\"name:start-end\", 1-based and inclusive. Without regions, only the index is built.", stderr)
+	output := fs.String("o", "", "write the FASTA to `file` instead of stdout")
+	width := fs.Int("n", fasta.DefaultLineWidth, "wrap sequences every `bases`; 0 for one line each")
+	revComp := fs.Bool("i", false, "reverse complement the sequences")
+	markStrand := fs.String("mark-strand", "rc", "add the strand of `type` to the names: rc, no, sign or custom,<pos>,<neg>")
+	regionFile := fs.String("r", "", "read regions from `file`, one per line")
+	bedFile := fs.String("b", "", "read regions from the BED `file`")
+	keepGoing := fs.Bool("c", false, "continue after a region whose sequence is missing")
+	indexPath := fs.String("fai-idx", "", "use the index in `file`, building it there if missing")
+	fs.StringVar(output, "output", "", "same as -o")
+	fs.IntVar(width, "length", fasta.DefaultLineWidth, "same as -n")
+	fs.BoolVar(revComp, "reverse-complement", false, "same as -i")
+	fs.StringVar(regionFile, "region-file", "", "same as -r")
+	fs.StringVar(bedFile, "bed", "", "same as -b")
+	fs.BoolVar(keepGoing, "continue", false, "same as -c")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return usageCode(err)
+	}
+	if len(positional) == 0 {
+		return usageError(fs, "no FASTA file given")
+	}
+	if *width < 0 {
+		return usageError(fs, fmt.Sprintf("invalid line length %d", *width))
+	}
+	posMark, negMark, err := parseMarkStrand(*markStrand)
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+	mark := posMark
+	if *revComp {
+		mark = negMark
+	}
+
+	// 2. Open the file, building its index if needed, like samtools does.
+	spec := adapter.OpenSpec{Path: positional[0], Index: *indexPath, Notify: warnTo(stderr, "[faidx] ")}
+	reader, err := fasta.NewIndexedReader(spec)
+	if err != nil {
+		fmt.Fprintf(stderr, "[faidx] Could not load fai index of %s: %v\n", positional[0], err)
+		return exitError
+	}
+	defer reader.Close()
+
+	// 3. Gather the regions: from the region file, the BED file, then the
+	// arguments.
+	var regions []faidxRegion
+	if *regionFile != "" {
+		if regions, err = readRegionFile(*regionFile, reader); err != nil {
+			fmt.Fprintf(stderr, "[faidx] Failed to read region file %s: %v\n", *regionFile, err)
+			return exitError
+		}
+	}
+	if *bedFile != "" {
+		fromBed, err := readBedRegions(*bedFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "[faidx] Failed to read BED file %s: %v\n", *bedFile, err)
+			return exitError
+		}
+		regions = append(regions, fromBed...)
+	}
+	for _, text := range positional[1:] {
+		regions = append(regions, parseFaidxRegion(text, reader))
+	}
+	if len(regions) == 0 {
+		return exitOK
+	}
+
+	// 4. Write every region, stopping at the first that cannot be fetched.
+	out := bufio.NewWriter(stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "[faidx] Failed to open %s for writing: %v\n", *output, err)
+			return exitError
+		}
+		defer f.Close()
+		out = bufio.NewWriter(f)
+	}
+	status := exitOK
+	for _, reg := range regions {
+		if err := writeFaidxRegion(out, stderr, reader, reg, mark, *width, *revComp, *keepGoing); err != nil {
+			status = exitError
+			break
+		}
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "[faidx] Failed to write output: %v\n", err)
+		return exitError
+	}
+	return status
+}
+
+// writeFaidxRegion writes the record of a region, warning about one that is
+// empty or shorter than asked. As in samtools, the header is written even
+// when the sequence cannot be fetched; a missing sequence is skipped when
+// keepGoing is set, and is an error otherwise.
+func writeFaidxRegion(out io.Writer, stderr io.Writer, reader *fasta.IndexedReader, reg faidxRegion, mark string, width int, revComp, keepGoing bool) error {
+	header := reg.name + mark
+	rec, found := reader.Index[reg.ref]
+	if reg.err != nil || !found {
+		if err := fasta.WriteRecord(out, header, nil, width); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "[faidx] Failed to fetch sequence in %s\n", reg.name)
+		if reg.err == nil && keepGoing {
+			return nil
+		}
+		return fmt.Errorf("could not fetch %s", reg.name)
+	}
+
+	end := reg.end
+	if end < 0 {
+		end = rec.Length
+	}
+	var seq bytes.Buffer
+	if err := reader.FetchRange(reg.ref, reg.start, end, &seq); err != nil {
+		fmt.Fprintf(stderr, "[faidx] Failed to fetch sequence in %s\n", reg.name)
+		return err
+	}
+	switch {
+	case seq.Len() == 0:
+		fmt.Fprintf(stderr, "[faidx] Zero length sequence: %s\n", reg.name)
+	case reg.explicit && int64(seq.Len()) != end-reg.start:
+		fmt.Fprintf(stderr, "[faidx] Truncated sequence: %s\n", reg.name)
+	}
+	bases := seq.Bytes()
+	if revComp {
+		bases = fasta.ReverseComplement(bases)
+	}
+	return fasta.WriteRecord(out, header, bases, width)
+}
+
+// parseFaidxRegion parses a region as samtools does: a sequence name as a
+// whole comes first, so names with colons work, then "ref:start-end".
+func parseFaidxRegion(text string, reader *fasta.IndexedReader) faidxRegion {
+	if _, ok := reader.Index[text]; ok {
+		return faidxRegion{name: text, ref: text, end: -1}
+	}
+	reg, err := adapter.ParseRegion(text)
+	if err != nil {
+		return faidxRegion{name: text, err: err}
+	}
+	r := faidxRegion{name: text, ref: reg.Ref, start: reg.Start - 1, end: -1}
+	if reg.End > 0 {
+		r.end, r.explicit = reg.End, true
+	}
+	return r
+}
+
+// readRegionFile reads a region per line, skipping blank lines.
+func readRegionFile(path string, reader *fasta.IndexedReader) ([]faidxRegion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var regions []faidxRegion
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			regions = append(regions, parseFaidxRegion(line, reader))
+		}
+	}
+	return regions, scanner.Err()
+}
+
+// readBedRegions reads the intervals of a BED file. Their records are named
+// like regions, "ref:start-end" in 1-based coordinates.
+func readBedRegions(path string) ([]faidxRegion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var regions []faidxRegion
+	p := bed.NewParser(f)
+	for {
+		rec, err := p.Next()
+		if err == io.EOF {
+			return regions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := adapter.Region{Ref: rec.Chrom, Start: rec.Start + 1, End: rec.End}.String()
+		regions = append(regions, faidxRegion{name: name, ref: rec.Chrom, start: rec.Start, end: rec.End, explicit: true})
+	}
+}
+
+// parseMarkStrand returns the suffixes added to the names of forward and
+// reverse records for a --mark-strand value.
+func parseMarkStrand(s string) (pos, neg string, err error) {
+	switch s {
+	case "rc":
+		return "", "/rc", nil
+	case "no":
+		return "", "", nil
+	case "sign":
+		return "(+)", "(-)", nil
+	}
+	if custom, ok := strings.CutPrefix(s, "custom,"); ok {
+		if pos, neg, ok := strings.Cut(custom, ","); ok {
+			return pos, neg, nil
+		}
+	}
+	return "", "", fmt.Errorf("unknown --mark-strand option %q: expected rc, no, sign or custom,<pos>,<neg>", s)
+}
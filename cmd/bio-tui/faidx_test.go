@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// faidxFasta is samtools.fa of the index tests. The expected outputs follow
+// samtools faidx, down to its warnings.
+const faidxFasta = `>chr1 Homo sapiens chromosome 1
+ACGTACGTAC
+ACGTACGTAC
+ACG
+>chr2	tab separated description
+NNNNNNNNNN
+NNNNN
+>chrM
+ACGTAC
+`
+
+func TestFaidx(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ref.fa")
+	if err := os.WriteFile(path, []byte(faidxFasta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	regions := filepath.Join(dir, "regions.txt")
+	if err := os.WriteFile(regions, []byte("chrM:2-3\n\nchr2:14\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	intervals := filepath.Join(dir, "regions.bed")
+	if err := os.WriteFile(intervals, []byte("track name=x\nchr1\t0\t5\tfirst\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		args   []string
+		out    string
+		stderr string
+		status int
+	}{
+		{"whole sequences", []string{path, "chrM", "chr1"}, ">chrM\nACGTAC\n>chr1\nACGTACGTACACGTACGTACACG\n", "", 0},
+		{"region across lines", []string{path, "chr1:9-12", "-n", "3"}, ">chr1:9-12\nACA\nC\n", "", 0},
+		{"reverse complement", []string{"-i", path, "chr1:1,000-1,002", "chrM:1-4"},
+			">chr1:1,000-1,002/rc\n>chrM:1-4/rc\nACGT\n", "[faidx] Zero length sequence: chr1:1,000-1,002\n", 0},
+		{"open end", []string{path, "chr1:20-", "chrM:4-"}, ">chr1:20-\nCACG\n>chrM:4-\nTAC\n", "", 0},
+		{"strand signs", []string{"--mark-strand", "sign", path, "chrM:4"}, ">chrM:4(+)\nTAC\n", "", 0},
+		{"custom marks", []string{"-i", "--mark-strand", "custom,+,-", path, "chrM:5-6"}, ">chrM:5-6-\nGT\n", "", 0},
+		{"truncated", []string{path, "chrM:5-10"}, ">chrM:5-10\nAC\n", "[faidx] Truncated sequence: chrM:5-10\n", 0},
+		{"missing", []string{path, "chrX", "chrM"}, ">chrX\n", "[faidx] Failed to fetch sequence in chrX\n", 1},
+		{"continue", []string{"-c", path, "chrX", "chrM"}, ">chrX\n>chrM\nACGTAC\n", "[faidx] Failed to fetch sequence in chrX\n", 0},
+		{"region file and BED", []string{"-r", regions, "--bed", intervals, path, "chrM:6"},
+			">chrM:2-3\nCG\n>chr2:14\nNN\n>chr1:1-5\nACGTA\n>chrM:6\nC\n", "", 0},
+	}
+	for _, c := range cases {
+		var out, stderr bytes.Buffer
+		status := faidx(c.args, &out, &stderr)
+		if status != c.status || out.String() != c.out || stderr.String() != c.stderr {
+			t.Errorf("%s: expected %d with\n%s%s\ngot %d with\n%s%s", c.name, c.status, c.out, c.stderr, status, out.String(), stderr.String())
+		}
+	}
+}
+
+// TestFaidx_MixedEndings extracts from a file whose lines end in both LF and
+// CRLF, with the index built by the first run and read from disk by the
+// second, which does not record the mixed endings.
+func TestFaidx_MixedEndings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mixed.fa")
+	if err := os.WriteFile(path, []byte(">chr1\r\nACGTACGTAC\r\nACGTACGTAC\nACG\r\n>chr2\nAC\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, run := range []string{"built", "saved"} {
+		var out, stderr bytes.Buffer
+		if status := faidx([]string{path, "chr1:21-23", "chr2"}, &out, &stderr); status != 0 {
+			t.Fatalf("index %s: expected 0, got %d: %s", run, status, stderr.String())
+		}
+		if want := ">chr1:21-23\nACG\n>chr2\nAC\n"; out.String() != want {
+			t.Errorf("index %s: expected\n%sgot\n%s", run, want, out.String())
+		}
+		if _, err := os.Stat(path + ".fai"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
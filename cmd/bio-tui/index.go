@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/index"
+)
+
+// indexFiles builds the indexes of FASTA files that have none, or of every
+// file with -f, and prints where each index is.
+func indexFiles(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("index", "[options] <fasta-file>...",
+		"Build the .fai index of FASTA files that have none and print where each\nindex is. Indexes go next to their file, or to the cache directory when\nthat is read-only, as in the viewer.", stderr)
+	force := fs.Bool("f", false, "rebuild indexes that exist, next to their file or at --fai-idx")
+	indexPath := fs.String("fai-idx", "", "use the index in `file` (one file only)")
+	where := fs.String("index-location", "auto", "where to put built indexes: auto or cache")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return usageCode(err)
+	}
+	if len(files) == 0 {
+		return usageError(fs, "no FASTA file given")
+	}
+	if *indexPath != "" && len(files) != 1 {
+		return usageError(fs, fmt.Sprintf("--fai-idx needs exactly one file, got %d", len(files)))
+	}
+	location, err := adapter.ParseIndexLocation(*where)
+	if err == nil && location == adapter.IndexMemory {
+		err = errors.New("indexes kept in memory are lost on exit")
+	}
+	if err != nil {
+		return usageError(fs, fmt.Sprintf("invalid --index-location: %v", err))
+	}
+
+	status := exitOK
+	for _, path := range files {
+		spec := adapter.OpenSpec{Path: path, Index: *indexPath, IndexLocation: location, Notify: warnTo(stderr, "")}
+		saved, err := buildIndex(spec, *force)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error indexing %s: %v\n", path, err)
+			status = exitError
+			continue
+		}
+		fmt.Fprintln(stdout, saved)
+	}
+	return status
+}
+
+// buildIndex returns where the index of a file is, building it if missing.
+// A forced build replaces the index next to the file, or at spec.Index.
+func buildIndex(spec adapter.OpenSpec, force bool) (string, error) {
+	if force {
+		target := spec.Index
+		if target == "" {
+			target = spec.Path + ".fai"
+		}
+		return target, index.BuildFai(context.Background(), spec.Path, target, nil)
+	}
+	r, err := fasta.NewIndexedReader(spec)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	if r.IndexPath == "" {
+		return "", errors.New("no place to save the index")
+	}
+	return r.IndexPath, nil
+}
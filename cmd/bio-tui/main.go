@@ -1,47 +1,179 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"runtime"
+	"runtime/debug"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/guillechuma/bio-tui/internal/adapter"
-	"github.com/guillechuma/bio-tui/internal/fasta"
-	"github.com/guillechuma/bio-tui/internal/ui"
 )
 
+// Exit codes shared by every command.
+const (
+	exitOK    = 0 // Success
+	exitError = 1 // The command failed, or found a file invalid
+	exitUsage = 2 // The command line is wrong
+)
+
+// version is set when building a release, with
+// -ldflags "-X main.version=v1.2.3".
+var version string
+
+// command is a subcommand of bio-tui.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands returns the subcommands, in the order the help lists them.
+func commands() []command {
+	return []command{
+		{"view", "Browse files in the terminal UI (the default)", view},
+		{"index", "Build the .fai index of FASTA files", indexFiles},
+		{"stats", "Print the length and composition of every sequence", stats},
+		{"faidx", "Extract regions, like samtools faidx", faidx},
+		{"export", "Export a region as a PNG figure or JSON", exportRegion},
+		{"convert", "Convert between FASTA and FASTQ", convert},
+		{"validate", "Check that files are well formed", validate},
+		{"version", "Print the version", printVersion},
+		{"help", "Show the help of a command", help},
+	}
+}
+
 func main() {
-	// 1. Check for a command-line argument for the file path.
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: bio-tui <fasta-file>")
-		os.Exit(1)
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command named by the first argument, or view when the first
+// argument is not a command, and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	case "-version", "--version":
+		return printVersion(nil, stdout, stderr)
+	}
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
 	}
-	filePath := os.Args[1]
+	return view(args, stdout, stderr)
+}
 
-	// 2. Set up your FastaAdapter.
-	fa := &fasta.FastaAdapter{}
-	var reader adapter.Reader = fa
+// usage writes the overview of the commands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: bio-tui [command] [options] [arguments]")
+	fmt.Fprintln(w, "\nA terminal viewer and toolkit for sequence files. Without a command, the\nfiles given are opened in the viewer.")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'bio-tui help <command>' for the options of a command.")
+	fmt.Fprintln(w, "Exit codes: 0 on success, 1 on errors and invalid files, 2 on usage errors.")
+}
 
-	// 3. Open the file and get the list of symbols.
-	spec := adapter.OpenSpec{Path: filePath}
-	if err := reader.Open(spec); err != nil {
-		log.Fatalf("Error opening file: %v", err)
+// help shows the help of a command, or the overview without one.
+func help(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stdout)
+		return exitOK
 	}
-	defer reader.Close()
+	for _, c := range commands() {
+		if c.name == args[0] && c.name != "help" {
+			return c.run([]string{"-h"}, stdout, stdout)
+		}
+	}
+	fmt.Fprintf(stderr, "Unknown command %q\n\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+// printVersion prints the version of the binary: the release it was built
+// for, or the module version go install recorded.
+func printVersion(_ []string, stdout, _ io.Writer) int {
+	v := version
+	if info, ok := debug.ReadBuildInfo(); ok && v == "" {
+		v = info.Main.Version
+	}
+	if v == "" {
+		v = "(devel)"
+	}
+	fmt.Fprintf(stdout, "bio-tui %s (%s %s/%s)\n", v, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return exitOK
+}
+
+// newFlagSet returns the flag set of a command. Its help shows the usage
+// line and description of the command, then its options.
+func newFlagSet(name, synopsis, description string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "Usage: bio-tui %s %s\n\n%s\n", name, synopsis, description)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(w, "\nOptions:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
 
-	symbols, err := reader.ListSymbols()
-	if err != nil {
-		log.Fatalf("Error listing symbols: %v", err)
+// usageCode returns the exit code for an error parsing the command line:
+// asking for help is not a failure.
+func usageCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
 	}
+	return exitUsage
+}
 
-	// 4. Create the TUI model with the data.
-	model := ui.NewModel(symbols, reader)
+// usageError reports a wrong command line with the usage of the command.
+func usageError(fs *flag.FlagSet, problem string) int {
+	fmt.Fprintf(fs.Output(), "%s\n\n", problem)
+	fs.Usage()
+	return exitUsage
+}
+
+// warnTo returns a Notify that writes warnings and errors to w, after
+// prefix, and drops the rest, for commands that run without the UI.
+func warnTo(w io.Writer, prefix string) adapter.Notify {
+	return func(level adapter.Level, text string) {
+		if level >= adapter.LevelWarn {
+			fmt.Fprintf(w, "%s%s\n", prefix, text)
+		}
+	}
+}
 
-	// 5. Create and run the Bubble Tea program.
-	// Using WithAltScreen restores the terminal to its original state on exit.
-	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		log.Fatalf("Error running program: %v", err)
+// parseInterspersed parses flags anywhere among the arguments, as getopt
+// does for samtools, and returns the other arguments in order. Everything
+// after "--" is an argument.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		remaining := fs.Args()
+		if len(remaining) == 0 {
+			return rest, nil
+		}
+		if parsed := len(args) - len(remaining); parsed > 0 && args[parsed-1] == "--" {
+			return append(rest, remaining...), nil
+		}
+		rest = append(rest, remaining[0])
+		args = remaining[1:]
 	}
 }
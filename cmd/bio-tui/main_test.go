@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir) // The default configuration
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ref := write("ref.fa", faidxFasta)
+	bad := write("bad.fa", ">a\nAC#T\n>a\nACGT\n")
+	reads := write("reads.fq", "@r1 lane 1\nACGT\n+\nII#I\n\n@r2\nNA\n+\n!!\n")
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	cases := []struct {
+		name   string
+		args   []string
+		status int
+		out    string // Expected stdout, or a part of it after "..."
+	}{
+		{"no arguments", nil, exitUsage, ""},
+		{"help", []string{"--help"}, exitOK, "...Commands:"},
+		{"command help", []string{"help", "faidx"}, exitOK, "...Usage: bio-tui faidx"},
+		{"unknown help", []string{"help", "nope"}, exitUsage, ""},
+		{"unknown flag", []string{"stats", "--nope", ref}, exitUsage, ""},
+		{"version", []string{"--version"}, exitOK, "...bio-tui "},
+		{"stats", []string{"stats", "--no-header", ref}, exitOK,
+			ref + "\tchr1\t23\t52.17\t0\t0.00\n" + ref + "\tchr2\t15\t0.00\t15\t0.00\n" + ref + "\tchrM\t6\t50.00\t0\t0.00\n"},
+		{"stats total", []string{"stats", "--total", ref}, exitOK,
+			"file\tname\tlength\tgc_percent\tn_bases\tmasked_percent\n" + ref + "\t*\t44\t51.72\t15\t0.00\n"},
+		{"index", []string{"index", ref}, exitOK, ref + ".fai\n"},
+		{"remote", []string{"faidx", srv.URL + "/ref.fa", "chrM:2-4"}, exitOK, ">chrM:2-4\nCGT\n"},
+		{"export without format", []string{"export", "-r", "chr1", ref}, exitUsage, ""},
+		{"export PNG", []string{"export", "--png", "-r", "chr1:2-10", "-o", "-", ref}, exitOK, "...\x89PNG\r\n"},
+		{"export JSON", []string{"export", "--json", "-r", "chr2:3-4", "-o", "-", ref}, exitOK,
+			"...\"region\": \"chr2:3-4\",\n  \"ref\": \"chr2\",\n  \"start\": 3,\n  \"end\": 4,\n  \"sequence\": \"NN\",\n"},
+		{"export both formats", []string{"export", "--png", "--json", "-r", "chr1", ref}, exitUsage, ""},
+		{"valid", []string{"validate", ref, reads}, exitOK,
+			ref + ": OK, 3 sequences, 44 bases\n" + reads + ": OK, 2 sequences, 6 bases\n"},
+		{"invalid", []string{"validate", ref, bad}, exitError,
+			"..." + bad + ": line 2, column 3: invalid character '#' in sequence a\n" +
+				bad + ": line 3: sequence a is already named on line 1\n" + bad + ": 2 problems\n"},
+		{"quiet", []string{"validate", "-q", bad}, exitError, ""},
+		{"unknown format", []string{"validate", ref + ".fai"}, exitUsage, ""},
+		{"FASTQ to FASTA", []string{"convert", reads}, exitOK, ">r1 lane 1\nACGT\n>r2\nNA\n"},
+		{"FASTA to FASTQ", []string{"convert", "--to", "fastq", "-q", "30", ref, "-n", "5"}, exitOK,
+			"...@chrM\nACGTAC\n+\n??????\n"},
+		{"rewrap", []string{"convert", "-n", "20", ref}, exitOK,
+			">chr1 Homo sapiens chromosome 1\nACGTACGTACACGTACGTAC\nACG\n>chr2\ttab separated description\nNNNNNNNNNNNNNNN\n>chrM\nACGTAC\n"},
+	}
+	for _, c := range cases {
+		var out, stderr bytes.Buffer
+		status := run(c.args, &out, &stderr)
+		got := out.String()
+		matches := got == c.out
+		if part, ok := strings.CutPrefix(c.out, "..."); ok {
+			matches = strings.Contains(got, part)
+		}
+		if status != c.status || !matches {
+			t.Errorf("%s: expected %d with %q, got %d with %q (stderr %q)", c.name, c.status, c.out, status, got, stderr.String())
+		}
+	}
+}
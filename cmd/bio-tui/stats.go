@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/summary"
+)
+
+// composition counts the bases written to it, so sequences are summarized
+// as they are read rather than loaded whole.
+type composition struct {
+	summary.Counts
+}
+
+func (c *composition) Write(p []byte) (int, error) {
+	c.Add(summary.Count(p))
+	return len(p), nil
+}
+
+// stats prints the length and composition of every sequence of FASTA files
+// as tab-separated columns.
+func stats(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("stats", "[options] <fasta-file>...",
+		"Print the length and composition of every sequence, as tab-separated\ncolumns: file, name, length, GC % of the non-N bases, N bases and\nsoft-masked (lowercase) %. Missing indexes are built first.", stderr)
+	total := fs.Bool("total", false, "print a line per file for all its sequences, named \"*\"")
+	noHeader := fs.Bool("no-header", false, "leave out the header line")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return usageCode(err)
+	}
+	if len(files) == 0 {
+		return usageError(fs, "no FASTA file given")
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+	if !*noHeader {
+		fmt.Fprintln(out, "file\tname\tlength\tgc_percent\tn_bases\tmasked_percent")
+	}
+	row := func(path, name string, c summary.Counts) {
+		fmt.Fprintf(out, "%s\t%s\t%d\t%.2f\t%d\t%.2f\n", path, name, c.Bases, c.GCFraction()*100, c.N, c.MaskedFraction()*100)
+	}
+	status := exitOK
+	for _, path := range files {
+		r, err := fasta.NewIndexedReader(adapter.OpenSpec{Path: path, Notify: warnTo(stderr, "")})
+		if err != nil {
+			fmt.Fprintf(stderr, "Error opening %s: %v\n", path, err)
+			status = exitError
+			continue
+		}
+		var all summary.Counts
+		for _, rec := range r.Records() {
+			var c composition
+			if err := r.FetchRange(rec.Name, 0, rec.Length, &c); err != nil {
+				fmt.Fprintf(stderr, "Error reading %s in %s: %v\n", rec.Name, path, err)
+				status = exitError
+				break
+			}
+			if !*total {
+				row(path, rec.Name, c.Counts)
+			}
+			all.Add(c.Counts)
+		}
+		if *total {
+			row(path, "*", all)
+		}
+		r.Close()
+	}
+	return status
+}
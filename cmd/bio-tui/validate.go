@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/guillechuma/bio-tui/internal/index"
+)
+
+// maxProblems is the most problems listed per file.
+const maxProblems = 20
+
+// report collects what is wrong with a file.
+type report struct {
+	problems []string
+	warnings []string
+	records  int
+	bases    int64
+}
+
+func (r *report) problem(format string, args ...any) {
+	r.problems = append(r.problems, fmt.Sprintf(format, args...))
+}
+
+func (r *report) warn(format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
+
+// validate checks that FASTA and FASTQ files are well formed, printing the
+// problems of each file.
+func validate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("validate", "[options] <file>...",
+		"Check that FASTA and FASTQ files are well formed: records start with a\nnamed header, sequences hold only sequence letters, FASTA names are unique\nand line lengths can be indexed, FASTQ qualities match their bases. The\nproblems of each file are printed, and the exit code is 1 if any is found.", stderr)
+	format := fs.String("format", "", "`format` of every file: fasta or fastq (default from the extension)")
+	quiet := fs.Bool("q", false, "print nothing; only set the exit code")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return usageCode(err)
+	}
+	if len(files) == 0 {
+		return usageError(fs, "no file given")
+	}
+
+	status := exitOK
+	for _, path := range files {
+		kind, err := formatOf(path, *format)
+		if err != nil {
+			return usageError(fs, err.Error())
+		}
+		var rep report
+		if kind == formatFASTQ {
+			err = validateFastq(path, &rep)
+		} else {
+			err = validateFasta(path, &rep)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			status = exitError
+			continue
+		}
+		if len(rep.problems) > 0 {
+			status = exitError
+		}
+		if !*quiet {
+			printReport(stdout, path, rep)
+		}
+	}
+	return status
+}
+
+// printReport prints the problems and warnings of a file, then a summary.
+func printReport(w io.Writer, path string, rep report) {
+	for i, p := range rep.problems {
+		if i == maxProblems {
+			fmt.Fprintf(w, "%s: ... and %d more problems\n", path, len(rep.problems)-maxProblems)
+			break
+		}
+		fmt.Fprintf(w, "%s: %s\n", path, p)
+	}
+	for _, warning := range rep.warnings {
+		fmt.Fprintf(w, "%s: warning: %s\n", path, warning)
+	}
+	switch len(rep.problems) {
+	case 0:
+		fmt.Fprintf(w, "%s: OK, %d sequences, %d bases\n", path, rep.records, rep.bases)
+	case 1:
+		fmt.Fprintf(w, "%s: 1 problem\n", path)
+	default:
+		fmt.Fprintf(w, "%s: %d problems\n", path, len(rep.problems))
+	}
+}
+
+// validateFasta checks a FASTA file line by line, then its line lengths
+// with the index scan, which tells whether it can be indexed.
+func validateFasta(path string, rep *report) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 1. Check the headers and the sequence letters.
+	seen := make(map[string]int) // Line of the header of each name
+	name := ""
+	empty := false // The current sequence has no bases yet
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if header, ok := bytes.CutPrefix(text, []byte(">")); ok {
+			if empty {
+				rep.warn("sequence %s is empty", name)
+			}
+			name, empty = "", true
+			if fields := bytes.Fields(header); len(fields) > 0 {
+				name = string(fields[0])
+			}
+			rep.records++
+			switch first, dup := seen[name]; {
+			case name == "":
+				rep.problem("line %d: header without a name", line)
+			case dup:
+				rep.problem("line %d: sequence %s is already named on line %d", line, name, first)
+			default:
+				seen[name] = line
+			}
+			continue
+		}
+		if len(text) == 0 {
+			continue
+		}
+		if rep.records == 0 {
+			rep.problem("line %d: sequence before the first header", line)
+			continue
+		}
+		empty = false
+		rep.bases += int64(len(text))
+		if i := bytes.IndexFunc(text, func(r rune) bool { return !isSequenceLetter(r) }); i >= 0 {
+			rep.problem("line %d, column %d: invalid character %q in sequence %s", line, i+1, text[i], name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if empty {
+		rep.warn("sequence %s is empty", name)
+	}
+	if rep.records == 0 {
+		rep.problem("no sequences")
+		return nil
+	}
+
+	// 2. Check the line lengths.
+	records, err := index.ScanFai(context.Background(), path, nil)
+	if err != nil {
+		rep.problem("cannot be indexed: %v", err)
+		return nil
+	}
+	for _, rec := range records {
+		if rec.MixedEndings {
+			rep.warn("sequence %s mixes LF and CRLF line endings", rec.Name)
+		}
+	}
+	return nil
+}
+
+// validateFastq checks the records of a FASTQ file. A record that cannot be
+// parsed ends the check, as the records after it cannot be told apart.
+func validateFastq(path string, rep *report) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	next := sequenceReader(f, formatFASTQ)
+	for n := 1; ; n++ {
+		rec, err := next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			rep.problem("record %d: %v", n, err)
+			return nil
+		}
+		rep.records++
+		rep.bases += int64(len(rec.seq))
+		if i := bytes.IndexFunc(rec.seq, func(r rune) bool { return !isSequenceLetter(r) }); i >= 0 {
+			rep.problem("record %d (%s): invalid base %q at %d", n, rec.name, rec.seq[i], i+1)
+		}
+		if i := bytes.IndexFunc(rec.qual, func(r rune) bool { return r < '!' || r > '~' }); i >= 0 {
+			rep.problem("record %d (%s): invalid quality %q at %d", n, rec.name, rec.qual[i], i+1)
+		}
+	}
+}
+
+// isSequenceLetter reports whether r may appear in a sequence: a letter of
+// a nucleotide or amino acid code, a stop '*', or a gap '-' or '.'.
+func isSequenceLetter(r rune) bool {
+	return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r == '*' || r == '-' || r == '.'
+}
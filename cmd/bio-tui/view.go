@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bookmark"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/remote"
+	"github.com/guillechuma/bio-tui/internal/session"
+	"github.com/guillechuma/bio-tui/internal/stream"
+	"github.com/guillechuma/bio-tui/internal/ui"
+)
+
+// view opens files in the terminal UI, or resumes a session. It is the
+// default command.
+func view(args []string, stdout, stderr io.Writer) int {
+	// 1. Parse the command line: files to open, a session to resume, or both.
+	fs := newFlagSet("view", "[options] <fasta-file>...",
+		"Browse FASTA files in the terminal UI, each in its own tab. Without files,\nthe files of the session are reopened. Files can be http(s) URLs, read with\nrange requests along with the .fai index next to them. A file of \"-\" reads FASTA or FASTQ\nfrom stdin, and named pipes are read the same way: their records are listed\nas they arrive.", stderr)
+	sessionPath := fs.String("session", "", "restore the session saved in `file`, and save it there on exit")
+	indexPath := fs.String("index", "", "use the index in `file`, building it there if missing (one file only)")
+	indexLocation := fs.String("index-location", "", "where to keep built indexes: auto, cache or memory (default from the config, else auto)")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return usageCode(err)
+	}
+
+	var sess *session.Session
+	if *sessionPath != "" {
+		s, err := session.Load(*sessionPath)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(stderr, "Error loading session: %v\n", err)
+			return exitError
+		}
+		sess = s
+	}
+
+	var specs []adapter.OpenSpec
+	switch {
+	case len(files) > 0:
+		for _, path := range files {
+			specs = append(specs, adapter.OpenSpec{Path: path})
+		}
+	case sess != nil:
+		for _, tab := range sess.Tabs {
+			specs = append(specs, tab.File.Spec())
+		}
+	}
+	if len(specs) == 0 {
+		return usageError(fs, "no file to open")
+	}
+	if *indexPath != "" {
+		if len(specs) != 1 {
+			return usageError(fs, fmt.Sprintf("--index needs exactly one file, got %d", len(specs)))
+		}
+		specs[0].Index = *indexPath
+	}
+
+	// 2. Load the user configuration, reporting every problem before starting.
+	cfg, ok := loadConfig(stderr)
+	if !ok {
+		return exitError
+	}
+	if *indexLocation != "" {
+		cfg.Index.Location = *indexLocation
+	}
+	location, err := adapter.ParseIndexLocation(cfg.Index.Location)
+	if err != nil {
+		return usageError(fs, fmt.Sprintf("invalid --index-location: %v", err))
+	}
+
+	// 3. Open every file in its own tab, with the adapter of its format.
+	// A file whose index must be built first opens once the UI starts, so
+	// the build shows its progress and can be cancelled. Notices are shown
+	// once the UI starts too.
+	registry := adapter.NewRegistry(fasta.Format)
+	notifier := ui.NewNotifier()
+	open := opener(registry, cfg, sess, location, notifier)
+	tabs := ui.NewTabs(cfg, registry, open, notifier)
+	for _, spec := range specs {
+		if needsIndex(registry, spec) {
+			tabs.Load(spec)
+			continue
+		}
+		m, err := open(spec)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error opening %s: %v\n", spec.Path, err)
+			return exitError
+		}
+		tabs.Add(m, spec)
+	}
+	if sess != nil && len(files) == 0 {
+		tabs.Restore(sess.Active, sess.Sync)
+	}
+
+	// 4. Create and run the Bubble Tea program.
+	// Using WithAltScreen restores the terminal to its original state on exit.
+	// Clipboard copies write to the same output, between frames.
+	p := tea.NewProgram(tabs, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(ui.Stdout))
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error running program: %v\n", err)
+		return exitError
+	}
+
+	// 5. Save the jump histories and the session, both as the last one of
+	// each file and to the session file if one was given. There is nowhere
+	// left to report a failure in the bookmarks, so that is best effort.
+	done := final.(ui.Tabs)
+	if err := done.Err(); err != nil {
+		fmt.Fprintf(stderr, "Error opening %v\n", err)
+		return exitError
+	}
+	if len(done.Models()) == 0 {
+		return exitOK // Every file was cancelled before it opened
+	}
+	for _, m := range done.Models() {
+		m.Bookmarks().Save()
+		m.Close()
+	}
+	saveSession(done, *sessionPath, stderr)
+	return exitOK
+}
+
+// loadConfig loads the user configuration, reporting every problem in it.
+func loadConfig(stderr io.Writer) (config.Config, bool) {
+	cfgPath, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return config.Config{}, false
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid configuration in %v\n", err)
+		return config.Config{}, false
+	}
+	return cfg, true
+}
+
+// needsIndex reports whether a file may have to be indexed before it opens:
+// its index is not next to it, nor where the spec says it is. Streams are
+// never indexed. URLs are opened in the background too, as their index has
+// to be downloaded.
+func needsIndex(registry *adapter.Registry, spec adapter.OpenSpec) bool {
+	if stream.IsStream(spec.Path) {
+		return false
+	}
+	if remote.IsURL(spec.Path) {
+		return true
+	}
+	if spec.Index != "" {
+		_, err := os.Stat(spec.Index)
+		return err != nil
+	}
+	format, ok := registry.Lookup(spec.Path)
+	return ok && len(format.Indexes) > 0 && format.Index(spec.Path) == ""
+}
+
+// opener returns the function that opens a file into a viewer: the adapter
+// of its format, its bookmarks and its saved state. Indexes that have to be
+// built are kept at location. Opened files are added to the recent files
+// list. Warnings go to the notifier. Streams are read as they arrive, and
+// keep neither bookmarks nor state, since they cannot be read again.
+func opener(registry *adapter.Registry, cfg config.Config, sess *session.Session, location adapter.IndexLocation, notifier *ui.Notifier) ui.OpenFunc {
+	return func(spec adapter.OpenSpec) (ui.Model, error) {
+		spec.Notify = notifier.Notify
+		spec.IndexLocation = location
+		notify := spec.Notify
+		if stream.IsStream(spec.Path) {
+			reader := &stream.Adapter{}
+			if err := reader.Open(spec); err != nil {
+				return ui.Model{}, err
+			}
+			return ui.NewModel(nil, reader, cfg, bookmark.NewMemoryStore()), nil
+		}
+		reader, err := registry.Open(spec)
+		if err != nil {
+			return ui.Model{}, err
+		}
+		symbols, err := reader.ListSymbols()
+		if err != nil {
+			reader.Close()
+			return ui.Model{}, fmt.Errorf("could not list sequences: %w", err)
+		}
+
+		// Load the bookmarks and jump history saved for this file.
+		store, err := bookmark.Open(spec.Path)
+		if err != nil {
+			notify.Send(adapter.LevelWarn, "Bookmarks of %s will not be saved: %v", spec.Path, err)
+			store = bookmark.NewMemoryStore()
+		}
+
+		// Create the TUI model with the data, back in its saved state.
+		m := ui.NewModel(symbols, reader, cfg, store)
+		if view := savedView(spec, sess, notify); view != nil {
+			m.Restore(*view)
+		}
+		if err := session.AddRecent(spec.Path); err != nil {
+			notify.Send(adapter.LevelWarn, "Could not update the recent files: %v", err)
+		}
+		return m, nil
+	}
+}
+
+// savedView returns the saved state of a file: its tab in the session file
+// if it has one, or else where the file was last left.
+func savedView(spec adapter.OpenSpec, sess *session.Session, notify adapter.Notify) *session.View {
+	if sess != nil {
+		if tab := sess.Find(spec.Path); tab != nil {
+			return &tab.View
+		}
+	}
+
+	last, err := session.LoadLast(spec.Path)
+	if err != nil {
+		notify.Send(adapter.LevelWarn, "Could not restore the last session of %s: %v", spec.Path, err)
+	}
+	if last == nil || len(last.Tabs) == 0 {
+		return nil
+	}
+	return &last.Tabs[0].View
+}
+
+// saveSession writes the session of the finished program. Streams are left
+// out, since they cannot be opened again.
+func saveSession(final ui.Tabs, sessionPath string, stderr io.Writer) {
+	specs := final.Files()
+	s := &session.Session{Sync: final.Sync()}
+	for i, m := range final.Models() {
+		if stream.IsStream(specs[i].Path) {
+			continue
+		}
+		if i == final.Active() {
+			s.Active = len(s.Tabs)
+		}
+		tab := session.Tab{File: session.FromSpec(specs[i]), View: m.Session()}
+		s.Tabs = append(s.Tabs, tab)
+
+		last := &session.Session{Tabs: []session.Tab{tab}}
+		lastPath, err := session.LastPath(specs[i].Path)
+		if err == nil {
+			err = session.Save(lastPath, last)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "[warn] could not save the session of %s: %v\n", specs[i].Path, err)
+		}
+	}
+	if sessionPath != "" {
+		if err := session.Save(sessionPath, s); err != nil {
+			fmt.Fprintf(stderr, "Error saving session: %v\n", err)
+		}
+	}
+}
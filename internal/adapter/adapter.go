@@ -1,10 +1,117 @@
 package adapter
 
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
 // OpenSpec provides all the necessary information to open an adapter.
 type OpenSpec struct {
-	Path  string            // The primary file path (e.g., sample.bam, genes.gff)
-	Index string            // An optional, explicit path to an index file (e.g., .tbi, .bai)
-	Aux   map[string]string // A map for any other auxiliary files the adapter might need.
+	Path     string            // The primary file path (e.g., sample.bam, genes.gff)
+	Index    string            // An optional, explicit path to an index file (e.g., .tbi, .bai)
+	Aux      map[string]string // A map for any other auxiliary files the adapter might need.
+	Notify   Notify            // Receives notices while the adapter works; may be nil
+	Progress func(Progress)    // Receives the progress of long tasks, like building an index; may be nil
+	Context  context.Context   // Cancels long tasks; nil means they run to the end
+
+	// IndexLocation says where to keep an index that has to be built, when
+	// Index is not given.
+	IndexLocation IndexLocation
+}
+
+// IndexLocation says where an adapter keeps an index it has to build.
+// Wherever it is kept, an index already next to the file or in the cache
+// is used.
+type IndexLocation int
+
+const (
+	IndexAuto   IndexLocation = iota // Next to the file, else in the user cache, else in memory
+	IndexCache                       // In the user cache, else in memory
+	IndexMemory                      // In memory only, rebuilt every time
+)
+
+// IndexLocations lists the names of the index locations, in order.
+var IndexLocations = []string{"auto", "cache", "memory"}
+
+func (l IndexLocation) String() string {
+	if int(l) < len(IndexLocations) {
+		return IndexLocations[l]
+	}
+	return fmt.Sprintf("IndexLocation(%d)", int(l))
+}
+
+// ParseIndexLocation parses the name of an index location.
+func ParseIndexLocation(s string) (IndexLocation, error) {
+	i := slices.Index(IndexLocations, s)
+	if i < 0 {
+		return IndexAuto, fmt.Errorf("unknown index location %q (expected %s)", s, strings.Join(IndexLocations, ", "))
+	}
+	return IndexLocation(i), nil
+}
+
+// Ctx returns the context of the spec, or a background context if it has none.
+func (s OpenSpec) Ctx() context.Context {
+	if s.Context == nil {
+		return context.Background()
+	}
+	return s.Context
+}
+
+// Report sends progress, doing nothing when the spec has no Progress.
+func (s OpenSpec) Report(p Progress) {
+	if s.Progress != nil {
+		s.Progress(p)
+	}
+}
+
+// Progress reports how far a long task, such as building an index, has got.
+type Progress struct {
+	Task   string // What is being done, e.g. "Building index"
+	Done   int64  // Bytes processed so far
+	Total  int64  // Bytes to process; 0 when unknown
+	Detail string // What was found so far, e.g. "12 sequences"
+}
+
+// Fraction returns how much of the task is done, from 0 to 1, or -1 when
+// the total is unknown.
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return min(1, float64(p.Done)/float64(p.Total))
+}
+
+// Level is the severity of a notice.
+type Level int
+
+const (
+	LevelInfo Level = iota
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// Notify receives the notices an adapter reports while it works, such as
+// building a missing index, so the UI can show them.
+type Notify func(level Level, text string)
+
+// Send reports a notice, doing nothing when n is nil.
+func (n Notify) Send(level Level, format string, args ...any) {
+	if n != nil {
+		n(level, fmt.Sprintf(format, args...))
+	}
 }
 
 // Symbol represents an entry in a list of named features, like sequence IDs
@@ -13,7 +120,8 @@ type Symbol struct {
 	Length int64
 }
 
-// Region defines a genomic interval. It is half-open: [Start, End).
+// Region defines a genomic interval. Like a region string ("chr1:1-100"),
+// it is 1-based and inclusive.
 type Region struct {
 	Ref   string
 	Start int64
@@ -30,8 +138,45 @@ const (
 	CapCoverage                        // Can compute coverage track
 	CapPileup                          // Can compute a pileup track.
 	CapSymbols                         // Can look up features by name (e.g., gene ID).
+	CapTracks                          // Can compute interval tracks (e.g., N-runs).
 )
 
+// Interval is a feature on a reference sequence, such as a run of N bases.
+// Unlike Region, it is 0-based and half-open so it maps directly onto BED.
+type Interval struct {
+	Ref   string
+	Start int64
+	End   int64
+	Name  string
+}
+
+// Len returns the number of bases covered by the interval.
+func (iv Interval) Len() int64 { return iv.End - iv.Start }
+
+// Track is a named lane of intervals rendered alongside the sequence.
+type Track struct {
+	Name      string
+	Intervals []Interval
+}
+
+// Well-known track names. They double as the BED name column on export.
+const (
+	TrackGaps     = "gap"      // Runs of N bases (assembly gaps).
+	TrackSoftMask = "softmask" // Runs of lowercase (soft-masked) bases.
+)
+
+// TrackLabel returns the short label shown in the lane margin for a track.
+func TrackLabel(name string) string {
+	switch name {
+	case TrackGaps:
+		return "N-run"
+	case TrackSoftMask:
+		return "masked"
+	default:
+		return name
+	}
+}
+
 // Slice contains all the data for a given genomic region.
 // This is the primary data structure returned to the UI for rendering.
 type Slice struct {
@@ -40,6 +185,8 @@ type Slice struct {
 	// A generic map to hold summary stats for the slice.
 	// Keys could be "GC Content", "N Count", "Variant Count", etc.
 	Stats map[string]string
+	// Interval tracks overlapping the slice, keyed by track name.
+	Tracks []Track
 }
 
 // Reader is the universal interface for all file type adapters.
@@ -52,4 +199,22 @@ type Reader interface {
 	ListSymbols() ([]Symbol, error)
 	LookupSymbol(sym string) (Region, error)
 	IterRows(ch chan<- []string, stop <-chan struct{}) error
+	Track(ref, name string) ([]Interval, error)
+}
+
+// Remote is implemented by readers that may fetch their data over the
+// network. Viewers read only the part of a sequence on screen from remote
+// readers, rather than whole sequences.
+type Remote interface {
+	Remote() bool
+}
+
+// Stream is implemented by readers whose data is still arriving, such as
+// records piped to stdin. Their symbols grow as records are read.
+type Stream interface {
+	// Changes returns a channel that receives a value after records are
+	// added, and is closed once the input has ended.
+	Changes() <-chan struct{}
+	// Err returns why reading stopped before the end of the input, if it did.
+	Err() error
 }
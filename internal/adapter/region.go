@@ -0,0 +1,57 @@
+package adapter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseRegion parses a samtools-style region string: "ref", "ref:start" or
+// "ref:start-end", with 1-based inclusive coordinates. Thousands separators
+// are allowed ("chr1:1,000-2,000"). A missing start is 1, and a missing end,
+// as in "ref:start" or "ref:start-", is returned as 0, meaning the end of
+// the sequence.
+//
+// Names that contain colons, like HLA alleles, are kept whole when the part
+// after the last colon is not a valid range.
+func ParseRegion(s string) (Region, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Region{}, fmt.Errorf("empty region")
+	}
+
+	colon := strings.LastIndex(s, ":")
+	if colon < 0 {
+		return Region{Ref: s, Start: 1}, nil
+	}
+	ref, span := s[:colon], strings.ReplaceAll(s[colon+1:], ",", "")
+
+	startText, endText, hasEnd := strings.Cut(span, "-")
+	start, err := strconv.ParseInt(startText, 10, 64)
+	if err != nil {
+		return Region{Ref: s, Start: 1}, nil
+	}
+	end := int64(0)
+	hasEnd = hasEnd && endText != "" // "ref:start-" runs to the end, as in samtools
+	if hasEnd {
+		if end, err = strconv.ParseInt(endText, 10, 64); err != nil {
+			return Region{}, fmt.Errorf("invalid end in region %q", s)
+		}
+	}
+	if ref == "" {
+		return Region{}, fmt.Errorf("missing sequence name in region %q", s)
+	}
+	if start < 1 || (hasEnd && end < start) {
+		return Region{}, fmt.Errorf("invalid coordinates in region %q", s)
+	}
+	return Region{Ref: ref, Start: start, End: end}, nil
+}
+
+// String formats the region as "ref:start-end", or "ref:start" when it runs
+// to the end of the sequence.
+func (r Region) String() string {
+	if r.End == 0 {
+		return fmt.Sprintf("%s:%d", r.Ref, r.Start)
+	}
+	return fmt.Sprintf("%s:%d-%d", r.Ref, r.Start, r.End)
+}
@@ -0,0 +1,34 @@
+package adapter
+
+import "testing"
+
+func TestParseRegion(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected Region
+	}{
+		{"chr1", Region{Ref: "chr1", Start: 1}},
+		{"chr1:1000", Region{Ref: "chr1", Start: 1000}},
+		{"chr1:100-", Region{Ref: "chr1", Start: 100}},
+		{"chr1:1,000-", Region{Ref: "chr1", Start: 1000}},
+		{"chr1:1,000-2,000", Region{Ref: "chr1", Start: 1000, End: 2000}},
+		{"HLA-A*01:01", Region{Ref: "HLA-A*01", Start: 1}},
+		{"HLA-A*01:01:01:01:1-10", Region{Ref: "HLA-A*01:01:01:01", Start: 1, End: 10}},
+	}
+	for _, tc := range cases {
+		actual, err := ParseRegion(tc.input)
+		if err != nil {
+			t.Errorf("ParseRegion(%q) returned an unexpected error: %v", tc.input, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("ParseRegion(%q) = %+v, expected %+v", tc.input, actual, tc.expected)
+		}
+	}
+
+	for _, input := range []string{"", "chr1:0-10", "chr1:0-", "chr1:20-10", "chr1:5-x", ":5-"} {
+		if _, err := ParseRegion(input); err == nil {
+			t.Errorf("ParseRegion(%q) succeeded, expected an error", input)
+		}
+	}
+}
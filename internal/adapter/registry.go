@@ -0,0 +1,83 @@
+package adapter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/remote"
+)
+
+// Format describes a file format and the adapter that opens it.
+type Format struct {
+	Name       string        // Human-readable name, e.g. "FASTA"
+	Extensions []string      // File extensions with the dot, e.g. ".fa"
+	Indexes    []string      // Extensions appended to the file name by its index files, e.g. ".fai"
+	New        func() Reader // Creates an adapter for the format
+}
+
+// Index returns the path of the first index file found next to a file of
+// the format, or "" if there is none.
+func (f Format) Index(path string) string {
+	for _, ext := range f.Indexes {
+		if _, err := os.Stat(path + ext); err == nil {
+			return path + ext
+		}
+	}
+	return ""
+}
+
+// Registry maps file extensions to the formats that can open them.
+type Registry struct {
+	formats []Format
+}
+
+// NewRegistry creates a registry of the given formats. When two formats
+// claim an extension, the first one wins.
+func NewRegistry(formats ...Format) *Registry {
+	return &Registry{formats: formats}
+}
+
+// Lookup returns the format of a file, judged by its extension. Matching is
+// case-insensitive and prefers the longest extension, so ".fa.gz" beats ".gz".
+// The extension of a URL is the one of its path, ignoring its query.
+func (r *Registry) Lookup(path string) (Format, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	if remote.IsURL(path) {
+		name = strings.ToLower(remote.Base(path))
+	}
+	best, bestLen := Format{}, 0
+	for _, f := range r.formats {
+		for _, ext := range f.Extensions {
+			if len(ext) > bestLen && strings.HasSuffix(name, strings.ToLower(ext)) {
+				best, bestLen = f, len(ext)
+			}
+		}
+	}
+	return best, bestLen > 0
+}
+
+// Extensions returns every supported extension, sorted.
+func (r *Registry) Extensions() []string {
+	var exts []string
+	for _, f := range r.formats {
+		exts = append(exts, f.Extensions...)
+	}
+	slices.Sort(exts)
+	return slices.Compact(exts)
+}
+
+// Open opens a file with the adapter of its format.
+func (r *Registry) Open(spec OpenSpec) (Reader, error) {
+	f, ok := r.Lookup(spec.Path)
+	if !ok {
+		return nil, fmt.Errorf("unsupported file type %q (supported: %s)", filepath.Base(spec.Path), strings.Join(r.Extensions(), ", "))
+	}
+	reader := f.New()
+	if err := reader.Open(spec); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
@@ -0,0 +1,26 @@
+package adapter
+
+import "testing"
+
+func TestRegistryLookup(t *testing.T) {
+	fasta := Format{Name: "FASTA", Extensions: []string{".fa", ".fasta"}}
+	gzip := Format{Name: "gzip", Extensions: []string{".gz"}}
+	bgzf := Format{Name: "BGZF FASTA", Extensions: []string{".fa.gz"}}
+	r := NewRegistry(fasta, gzip, bgzf)
+
+	cases := map[string]string{
+		"genome.fa":        "FASTA",
+		"dir/GENOME.FASTA": "FASTA",
+		"genome.fa.gz":     "BGZF FASTA",
+		"reads.gz":         "gzip",
+		"notes.txt":        "",
+
+		"https://host/genome.fa?token=x": "FASTA",
+	}
+	for path, expected := range cases {
+		f, ok := r.Lookup(path)
+		if f.Name != expected || ok != (expected != "") {
+			t.Errorf("Lookup(%q) = %q, %v, expected %q", path, f.Name, ok, expected)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package bed
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Record holds a single BED interval. Coordinates are 0-based and half-open.
+type Record struct {
+	Chrom string // Name of the reference sequence
+	Start int64  // 0-based start position
+	End   int64  // Exclusive end position
+	Name  string // Optional name column; omitted from output when empty
+}
+
+// Writer writes BED records to an underlying writer.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter creates a new BED writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// Write writes a single record as a BED3 or BED4 line.
+func (w *Writer) Write(rec Record) error {
+	if rec.Start < 0 || rec.End < rec.Start {
+		return fmt.Errorf("invalid BED interval %s:%d-%d", rec.Chrom, rec.Start, rec.End)
+	}
+	var err error
+	if rec.Name != "" {
+		_, err = fmt.Fprintf(w.w, "%s\t%d\t%d\t%s\n", rec.Chrom, rec.Start, rec.End, rec.Name)
+	} else {
+		_, err = fmt.Fprintf(w.w, "%s\t%d\t%d\n", rec.Chrom, rec.Start, rec.End)
+	}
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
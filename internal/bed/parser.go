@@ -0,0 +1,67 @@
+package bed
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parser reads BED records from a reader.
+type Parser struct {
+	scanner *bufio.Scanner
+	line    int // Number of the last line read, for error messages
+}
+
+// NewParser creates a new BED parser.
+func NewParser(r io.Reader) *Parser {
+	return &Parser{
+		scanner: bufio.NewScanner(r),
+	}
+}
+
+// Next returns the next record, skipping blank, comment, "track" and
+// "browser" lines. Columns after the name are ignored.
+// It returns io.EOF when the stream is exhausted.
+func (p *Parser) Next() (Record, error) {
+	for p.scanner.Scan() {
+		p.line++
+		line := strings.TrimRight(p.scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, "track") || strings.HasPrefix(line, "browser") {
+			continue
+		}
+
+		// BED is tab-separated, but space-separated files are common too.
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			fields = strings.Fields(line)
+		}
+		if len(fields) < 3 {
+			return Record{}, fmt.Errorf("line %d: expected at least 3 columns, got %d", p.line, len(fields))
+		}
+
+		start, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return Record{}, fmt.Errorf("line %d: invalid start %q", p.line, fields[1])
+		}
+		end, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return Record{}, fmt.Errorf("line %d: invalid end %q", p.line, fields[2])
+		}
+		if start < 0 || end < start {
+			return Record{}, fmt.Errorf("line %d: invalid interval %d-%d", p.line, start, end)
+		}
+
+		rec := Record{Chrom: fields[0], Start: start, End: end}
+		if len(fields) > 3 {
+			rec.Name = fields[3]
+		}
+		return rec, nil
+	}
+	if err := p.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
@@ -0,0 +1,168 @@
+package bookmark
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/guillechuma/bio-tui/internal/bed"
+	"github.com/guillechuma/bio-tui/internal/state"
+)
+
+// maxHistory is the number of locations kept in the jump history.
+const maxHistory = 100
+
+// Bookmark is a named region of a reference sequence.
+type Bookmark struct {
+	Name  string `json:"name"`
+	Ref   string `json:"ref"`
+	Start int64  `json:"start"` // 1-based, inclusive
+	End   int64  `json:"end"`   // 1-based, inclusive
+	Note  string `json:"note,omitempty"`
+}
+
+// Region formats the bookmarked region as "ref:start-end".
+func (b Bookmark) Region() string {
+	return fmt.Sprintf("%s:%d-%d", b.Ref, b.Start, b.End)
+}
+
+// Location is a position that the viewer jumped to or from.
+type Location struct {
+	Ref string `json:"ref"`
+	Pos int64  `json:"pos"` // 0-based position of the cursor
+}
+
+// History records jumps between locations for back and forward navigation,
+// like a web browser: jumping somewhere new drops the forward entries.
+type History struct {
+	Entries []Location `json:"entries"`
+	Index   int        `json:"index"` // Entry of the current location
+}
+
+// Visit records a jump from one location to another.
+func (h *History) Visit(from, to Location) {
+	if len(h.Entries) > 0 {
+		h.Entries = h.Entries[:h.Index+1]
+		h.Entries[h.Index] = from
+	} else {
+		h.Entries = append(h.Entries, from)
+	}
+	h.Entries = append(h.Entries, to)
+	if len(h.Entries) > maxHistory {
+		h.Entries = h.Entries[len(h.Entries)-maxHistory:]
+	}
+	h.Index = len(h.Entries) - 1
+}
+
+// Back returns the location before the current one, remembering current as
+// the place to come forward to.
+func (h *History) Back(current Location) (Location, bool) {
+	if h.Index <= 0 || h.Index >= len(h.Entries) {
+		return Location{}, false
+	}
+	h.Entries[h.Index] = current
+	h.Index--
+	return h.Entries[h.Index], true
+}
+
+// Forward returns the location after the current one, remembering current
+// as the place to go back to.
+func (h *History) Forward(current Location) (Location, bool) {
+	if h.Index+1 >= len(h.Entries) {
+		return Location{}, false
+	}
+	h.Entries[h.Index] = current
+	h.Index++
+	return h.Entries[h.Index], true
+}
+
+// Store holds the bookmarks and jump history of one file.
+type Store struct {
+	Path      string     `json:"path"`     // Absolute path of the file
+	Checksum  string     `json:"checksum"` // See state.Checksum
+	Bookmarks []Bookmark `json:"bookmarks"`
+	History   History    `json:"history"`
+
+	file string // State file the store is saved to; empty keeps it in memory
+}
+
+// Open loads the bookmarks of a file from the state directory. A file seen
+// for the first time, or changed since, gets an empty store.
+func Open(path string) (*Store, error) {
+	id, err := state.Identify(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := state.File("bookmarks", id.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{}
+	if err := state.Load(file, s); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	s.Path, s.Checksum, s.file = id.Path, id.Checksum, file
+	return s, nil
+}
+
+// NewMemoryStore returns a store that is never saved, for when the state
+// directory cannot be used.
+func NewMemoryStore() *Store {
+	return &Store{}
+}
+
+// Save writes the store to its state file.
+func (s *Store) Save() error {
+	if s.file == "" {
+		return nil
+	}
+	return state.Save(s.file, s)
+}
+
+// Add appends a bookmark.
+func (s *Store) Add(b Bookmark) {
+	s.Bookmarks = append(s.Bookmarks, b)
+}
+
+// Delete removes the bookmark at index i.
+func (s *Store) Delete(i int) {
+	if i >= 0 && i < len(s.Bookmarks) {
+		s.Bookmarks = append(s.Bookmarks[:i], s.Bookmarks[i+1:]...)
+	}
+}
+
+// ReadBED reads bookmarks from a BED file. The name column becomes the
+// bookmark name; intervals without one are named after their region.
+func ReadBED(r io.Reader) ([]Bookmark, error) {
+	var bookmarks []Bookmark
+	p := bed.NewParser(r)
+	for {
+		rec, err := p.Next()
+		if err == io.EOF {
+			return bookmarks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// BED is 0-based and half-open; bookmarks are 1-based and inclusive.
+		b := Bookmark{Name: rec.Name, Ref: rec.Chrom, Start: rec.Start + 1, End: max(rec.End, rec.Start+1)}
+		if b.Name == "" {
+			b.Name = b.Region()
+		}
+		bookmarks = append(bookmarks, b)
+	}
+}
+
+// WriteBED writes bookmarks as BED4 intervals named after the bookmarks.
+// Notes have no BED column and are not written.
+func WriteBED(w io.Writer, bookmarks []Bookmark) error {
+	bw := bed.NewWriter(w)
+	for _, b := range bookmarks {
+		if err := bw.Write(bed.Record{Chrom: b.Ref, Start: b.Start - 1, End: b.End, Name: b.Name}); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,56 @@
+package bookmark
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestHistory_BackAndForward(t *testing.T) {
+	a, b, c := Location{"chr1", 10}, Location{"chr1", 500}, Location{"chr2", 0}
+
+	var h History
+	h.Visit(a, b)
+	h.Visit(Location{"chr1", 520}, c) // The cursor moved on b before jumping
+
+	if loc, ok := h.Back(c); !ok || loc != (Location{"chr1", 520}) {
+		t.Fatalf("Back() = %v, %v; expected the position left on chr1", loc, ok)
+	}
+	if loc, ok := h.Back(b); !ok || loc != a {
+		t.Fatalf("Back() = %v, %v; expected %v", loc, ok, a)
+	}
+	if _, ok := h.Back(a); ok {
+		t.Fatalf("Back() past the first entry succeeded")
+	}
+	if loc, ok := h.Forward(a); !ok || loc != b {
+		t.Fatalf("Forward() = %v, %v; expected %v", loc, ok, b)
+	}
+
+	// A new jump drops the forward entries.
+	h.Visit(b, Location{"chr3", 7})
+	if _, ok := h.Forward(Location{"chr3", 7}); ok {
+		t.Fatalf("Forward() after a new jump succeeded")
+	}
+}
+
+func TestBED_RoundTrip(t *testing.T) {
+	bookmarks := []Bookmark{
+		{Name: "promoter", Ref: "chr1", Start: 1, End: 100},
+		{Name: "chr2:5-5", Ref: "chr2", Start: 5, End: 5},
+	}
+	var buf bytes.Buffer
+	if err := WriteBED(&buf, bookmarks); err != nil {
+		t.Fatalf("WriteBED() returned an unexpected error: %v", err)
+	}
+	if expected := "chr1\t0\t100\tpromoter\nchr2\t4\t5\tchr2:5-5\n"; buf.String() != expected {
+		t.Fatalf("WriteBED() wrote %q, expected %q", buf.String(), expected)
+	}
+
+	actual, err := ReadBED(&buf)
+	if err != nil {
+		t.Fatalf("ReadBED() returned an unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, bookmarks) {
+		t.Errorf("ReadBED() = %+v, expected %+v", actual, bookmarks)
+	}
+}
@@ -0,0 +1,342 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// Theme holds the colors used by the TUI. Colors are ANSI 256 numbers
+// (e.g. "205") or hex values, #RGB or #RRGGBB (e.g. "#F5A" or "#FF5FAF").
+type Theme struct {
+	Border       string            `toml:"border"`        // Border of inactive panes
+	ActiveBorder string            `toml:"active_border"` // Border of the focused pane
+	Highlight    string            `toml:"highlight"`     // Selected items and the cursor
+	Lane         string            `toml:"lane"`          // Track lanes under the sequence
+	Bases        map[string]string `toml:"bases"`         // Optional custom base palette, e.g. A = "#00FF00"
+}
+
+// Layout holds the default pane proportions.
+type Layout struct {
+	ListWidth       float64 `toml:"list_width"`       // Fraction of the width used by the sequence list
+	StatsHeight     int     `toml:"stats_height"`     // Height of the stats pane, including its frame
+	GapsHeight      int     `toml:"gaps_height"`      // Height of the N-run pane, including its frame
+	BookmarksHeight int     `toml:"bookmarks_height"` // Height of the bookmark pane, including its frame
+}
+
+// Display holds the default display options.
+type Display struct {
+	ShowGaps       bool   `toml:"show_gaps"`       // Open the N-run panel on startup
+	SoftMaskLane   bool   `toml:"soft_mask_lane"`  // Draw the soft-masked lane
+	Uppercase      bool   `toml:"uppercase"`       // Show soft-masked bases in uppercase
+	Palette        string `toml:"palette"`         // Nucleotide palette
+	ProteinPalette string `toml:"protein_palette"` // Protein palette
+}
+
+// Index says where indexes built by bio-tui are kept.
+type Index struct {
+	Location string `toml:"location"` // auto, cache or memory; see adapter.IndexLocation
+}
+
+// Config is the resolved user configuration, merged over the defaults.
+type Config struct {
+	Theme   Theme
+	Keys    map[string][]string // Action name to the keys bound to it
+	Layout  Layout
+	Display Display
+	Index   Index
+}
+
+// file mirrors the on-disk layout of the config file.
+type file struct {
+	Theme   string              `toml:"theme"`
+	Themes  map[string]Theme    `toml:"themes"`
+	Keys    map[string][]string `toml:"keys"`
+	Layout  Layout              `toml:"layout"`
+	Display Display             `toml:"display"`
+	Index   Index               `toml:"index"`
+}
+
+// DefaultTheme is the name of the built-in theme.
+const DefaultTheme = "default"
+
+// Actions lists every bindable action with its default keys.
+var Actions = map[string][]string{
+	"quit":             {"q", "ctrl+c"},
+	"focus_next":       {"tab"},
+	"cursor_left":      {"left", "h"},
+	"cursor_right":     {"right", "l"},
+	"cursor_up":        {"up", "k"},
+	"cursor_down":      {"down", "j"},
+	"zoom_in":          {"+", "="},
+	"zoom_out":         {"-"},
+	"visual":           {"v"},
+	"copy":             {"y"},
+	"copy_fasta":       {"Y"},
+	"copy_revcomp":     {"r"},
+	"export_selection": {"B"},
+	"add_bookmark":     {"a"},
+	"toggle_bookmarks": {"'"},
+	"rename_bookmark":  {"R"},
+	"edit_note":        {"e"},
+	"delete_bookmark":  {"x"},
+	"import_bookmarks": {"I"},
+	"export_bookmarks": {"O"},
+	"history_back":     {"["},
+	"history_forward":  {"]"},
+	"next_tab":         {"ctrl+n", "}"},
+	"prev_tab":         {"ctrl+p", "{"},
+	"goto_region":      {":"},
+	"toggle_sync":      {"S"},
+	"open_file":        {"o"},
+	"message_log":      {"M"},
+	"toggle_list":      {"L"},
+	"toggle_stats":     {"T"},
+	"toggle_stacked":   {"|"},
+	"grow_list":        {">"},
+	"shrink_list":      {"<"},
+	"grow_stats":       {"K"},
+	"shrink_stats":     {"J"},
+	"toggle_gaps":      {"N"},
+	"toggle_soft_mask": {"m"},
+	"toggle_uppercase": {"U"},
+	"cycle_palette":    {"c"},
+	"jump":             {"enter"},
+	"export_bed":       {"b"},
+	"export_png":       {"P"},
+	"export_json":      {"E"},
+	"help":             {"?"},
+}
+
+// Palette names accepted in the display section.
+var (
+	NucleotidePalettes = []string{"default", "okabe-ito", "mono"}
+	ProteinPalettes    = []string{"clustal", "zappo", "hydrophobicity", "mono"}
+)
+
+// Default returns the built-in configuration.
+func Default() Config {
+	keys := make(map[string][]string, len(Actions))
+	for action, k := range Actions {
+		keys[action] = slices.Clone(k)
+	}
+	return Config{
+		Theme: Theme{
+			Border:       "240", // A nice dim gray
+			ActiveBorder: "205", // A vibrant pink/magenta
+			Highlight:    "205",
+			Lane:         "214", // An amber that stands out from bases
+		},
+		Keys: keys,
+		Layout: Layout{
+			ListWidth:       1.0 / 3,
+			StatsHeight:     7,
+			GapsHeight:      12,
+			BookmarksHeight: 12,
+		},
+		Display: Display{
+			Palette:        NucleotidePalettes[0],
+			ProteinPalette: ProteinPalettes[0],
+		},
+		Index: Index{
+			Location: adapter.IndexAuto.String(),
+		},
+	}
+}
+
+// Path returns the location of the config file,
+// e.g. $XDG_CONFIG_HOME/bio-tui/config.toml.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "bio-tui", "config.toml"), nil
+}
+
+// Load reads the config file at path and merges it over the defaults.
+// A missing file is not an error. All validation problems are reported
+// together, one per line.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	var f file
+	md, err := toml.Decode(string(data), &f)
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var errs []error
+	for _, key := range md.Undecoded() {
+		errs = append(errs, fmt.Errorf("unknown setting %q", key.String()))
+	}
+
+	// Theme: a named theme overrides the default colors it sets.
+	if f.Theme != "" && f.Theme != DefaultTheme {
+		theme, ok := f.Themes[f.Theme]
+		if !ok {
+			errs = append(errs, fmt.Errorf("theme %q is not defined in [themes]", f.Theme))
+		} else {
+			cfg.Theme = mergeTheme(cfg.Theme, theme)
+		}
+	}
+
+	// Keys: only the actions present in the file are rebound.
+	for action, keys := range f.Keys {
+		if _, ok := Actions[action]; !ok {
+			errs = append(errs, fmt.Errorf("unknown action %q in [keys]", action))
+			continue
+		}
+		if len(keys) == 0 {
+			errs = append(errs, fmt.Errorf("action %q has no keys", action))
+			continue
+		}
+		cfg.Keys[action] = keys
+	}
+
+	// Layout and display: only the settings present in the file override the defaults.
+	if md.IsDefined("layout", "list_width") {
+		cfg.Layout.ListWidth = f.Layout.ListWidth
+	}
+	if md.IsDefined("layout", "stats_height") {
+		cfg.Layout.StatsHeight = f.Layout.StatsHeight
+	}
+	if md.IsDefined("layout", "gaps_height") {
+		cfg.Layout.GapsHeight = f.Layout.GapsHeight
+	}
+	if md.IsDefined("layout", "bookmarks_height") {
+		cfg.Layout.BookmarksHeight = f.Layout.BookmarksHeight
+	}
+	cfg.Display.ShowGaps = f.Display.ShowGaps
+	cfg.Display.SoftMaskLane = f.Display.SoftMaskLane
+	cfg.Display.Uppercase = f.Display.Uppercase
+	if f.Display.Palette != "" {
+		cfg.Display.Palette = f.Display.Palette
+	}
+	if f.Display.ProteinPalette != "" {
+		cfg.Display.ProteinPalette = f.Display.ProteinPalette
+	}
+	if f.Index.Location != "" {
+		cfg.Index.Location = f.Index.Location
+	}
+
+	errs = append(errs, cfg.Validate()...)
+	if len(errs) > 0 {
+		return cfg, fmt.Errorf("%s:\n%w", path, errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// mergeTheme returns base with every color set in override replaced.
+func mergeTheme(base, override Theme) Theme {
+	if override.Border != "" {
+		base.Border = override.Border
+	}
+	if override.ActiveBorder != "" {
+		base.ActiveBorder = override.ActiveBorder
+	}
+	if override.Highlight != "" {
+		base.Highlight = override.Highlight
+	}
+	if override.Lane != "" {
+		base.Lane = override.Lane
+	}
+	if len(override.Bases) > 0 {
+		bases := maps.Clone(base.Bases)
+		if bases == nil {
+			bases = make(map[string]string, len(override.Bases))
+		}
+		maps.Copy(bases, override.Bases)
+		base.Bases = bases
+	}
+	return base
+}
+
+// Validate checks the resolved configuration and returns every problem found.
+func (c Config) Validate() []error {
+	var errs []error
+
+	colors := map[string]string{
+		"border":        c.Theme.Border,
+		"active_border": c.Theme.ActiveBorder,
+		"highlight":     c.Theme.Highlight,
+		"lane":          c.Theme.Lane,
+	}
+	for base, color := range c.Theme.Bases {
+		if len(base) != 1 {
+			errs = append(errs, fmt.Errorf("base palette key %q must be a single character", base))
+		}
+		colors["bases."+base] = color
+	}
+	for _, name := range slices.Sorted(maps.Keys(colors)) {
+		if !isColor(colors[name]) {
+			errs = append(errs, fmt.Errorf("theme color %s = %q is not an ANSI number (0-255), #RGB or #RRGGBB", name, colors[name]))
+		}
+	}
+
+	// The same key cannot trigger two actions.
+	owner := make(map[string]string)
+	for _, action := range slices.Sorted(maps.Keys(c.Keys)) {
+		for _, k := range c.Keys[action] {
+			if other, ok := owner[k]; ok {
+				errs = append(errs, fmt.Errorf("key %q is bound to both %q and %q", k, other, action))
+				continue
+			}
+			owner[k] = action
+		}
+	}
+
+	if c.Layout.ListWidth <= 0 || c.Layout.ListWidth >= 1 {
+		errs = append(errs, fmt.Errorf("layout.list_width must be between 0 and 1, got %g", c.Layout.ListWidth))
+	}
+	if c.Layout.StatsHeight < 3 {
+		errs = append(errs, fmt.Errorf("layout.stats_height must be at least 3, got %d", c.Layout.StatsHeight))
+	}
+	if c.Layout.GapsHeight < 5 {
+		errs = append(errs, fmt.Errorf("layout.gaps_height must be at least 5, got %d", c.Layout.GapsHeight))
+	}
+	// The bookmark pane needs room for one bookmark and the name editor.
+	if c.Layout.BookmarksHeight < 12 {
+		errs = append(errs, fmt.Errorf("layout.bookmarks_height must be at least 12, got %d", c.Layout.BookmarksHeight))
+	}
+
+	if !slices.Contains(NucleotidePalettes, c.Display.Palette) {
+		errs = append(errs, fmt.Errorf("display.palette %q is not one of %s", c.Display.Palette, strings.Join(NucleotidePalettes, ", ")))
+	}
+	if !slices.Contains(ProteinPalettes, c.Display.ProteinPalette) {
+		errs = append(errs, fmt.Errorf("display.protein_palette %q is not one of %s", c.Display.ProteinPalette, strings.Join(ProteinPalettes, ", ")))
+	}
+	if _, err := adapter.ParseIndexLocation(c.Index.Location); err != nil {
+		errs = append(errs, fmt.Errorf("index.location: %w", err))
+	}
+	return errs
+}
+
+// hexColor matches the #RGB and #RRGGBB forms of hex colors.
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// isColor reports whether s is an ANSI 256 color number or a hex color.
+func isColor(s string) bool {
+	if hexColor.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
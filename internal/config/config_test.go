@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("could not write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_MergesOverDefaults(t *testing.T) {
+	path := writeConfig(t, `
+theme = "dark"
+
+[themes.dark]
+active_border = "#FF8800"
+
+[keys]
+quit = ["ctrl+q"]
+
+[layout]
+stats_height = 9
+
+[display]
+palette = "okabe-ito"
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned an unexpected error: %v", err)
+	}
+
+	if cfg.Theme.ActiveBorder != "#FF8800" || cfg.Theme.Border != "240" {
+		t.Errorf("theme not merged: %+v", cfg.Theme)
+	}
+	if got := cfg.Keys["quit"]; len(got) != 1 || got[0] != "ctrl+q" {
+		t.Errorf("quit keys: expected [ctrl+q], got %v", got)
+	}
+	if got := cfg.Keys["focus_next"]; len(got) != 1 || got[0] != "tab" {
+		t.Errorf("focus_next keys should keep the default, got %v", got)
+	}
+	if cfg.Layout.StatsHeight != 9 || cfg.Layout.ListWidth != 1.0/3 {
+		t.Errorf("layout not merged: %+v", cfg.Layout)
+	}
+	if cfg.Display.Palette != "okabe-ito" {
+		t.Errorf("palette: expected okabe-ito, got %s", cfg.Display.Palette)
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	path := writeConfig(t, `
+theme = "missing"
+colour = "red"
+
+[keys]
+jump = ["q"]
+fly = ["f"]
+
+[layout]
+list_width = 2.0
+
+[index]
+location = "beside"
+`)
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Load() should have failed")
+	}
+	for _, want := range []string{`"colour"`, `theme "missing"`, `action "fly"`, `key "q"`, "list_width", "index.location"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "none.toml"))
+	if err != nil {
+		t.Fatalf("Load() returned an unexpected error: %v", err)
+	}
+	if cfg.Layout.StatsHeight != 7 {
+		t.Errorf("expected defaults, got %+v", cfg.Layout)
+	}
+}
+
+func TestLoad_ThemeColors(t *testing.T) {
+	tests := []struct {
+		color string
+		valid bool
+	}{
+		{"205", true},
+		{"0", true},
+		{"#F5A", true},
+		{"#ff5faf", true},
+		{"256", false},
+		{"-1", false},
+		{"#FF5F", false},
+		{"#GGG", false},
+		{"FF5FAF", false},
+		{"pink", false},
+	}
+	for _, tt := range tests {
+		path := writeConfig(t, "theme = \"mine\"\n\n[themes.mine]\nborder = \""+tt.color+"\"\n")
+		cfg, err := Load(path)
+		switch {
+		case tt.valid && (err != nil || cfg.Theme.Border != tt.color):
+			t.Errorf("%q: expected a valid color, got %v", tt.color, err)
+		case !tt.valid && (err == nil || !strings.Contains(err.Error(), "#RGB or #RRGGBB")):
+			t.Errorf("%q: expected an error naming the color forms, got %v", tt.color, err)
+		}
+	}
+}
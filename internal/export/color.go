@@ -0,0 +1,67 @@
+package export
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// ansiColors are the first 16 ANSI colors as xterm shows them.
+var ansiColors = [16]uint32{
+	0x000000, 0xcd0000, 0x00cd00, 0xcdcd00, 0x0000ee, 0xcd00cd, 0x00cdcd, 0xe5e5e5,
+	0x7f7f7f, 0xff0000, 0x00ff00, 0xffff00, 0x5c5cff, 0xff00ff, 0x00ffff, 0xffffff,
+}
+
+// cubeLevels are the intensities of the 6x6x6 color cube of the ANSI 256
+// colors.
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// parseColor converts a color of the configuration, an ANSI 256 number or
+// a hex value such as "#FF5FAF" or "#F5A", to RGB.
+func parseColor(s string) (color.RGBA, bool) {
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return color.RGBA{}, false
+		}
+		return rgb(uint32(v)), true
+	}
+
+	n, err := strconv.Atoi(s)
+	switch {
+	case err != nil || n < 0 || n > 255:
+		return color.RGBA{}, false
+	case n < 16:
+		return rgb(ansiColors[n]), true
+	case n < 232:
+		n -= 16
+		return color.RGBA{cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6], 0xff}, true
+	default:
+		gray := uint8(8 + 10*(n-232))
+		return color.RGBA{gray, gray, gray, 0xff}, true
+	}
+}
+
+// rgb converts a 0xRRGGBB value to a color.
+func rgb(v uint32) color.RGBA {
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+// colorOr returns a color of the configuration, or fallback when it is
+// empty or invalid.
+func colorOr(s string, fallback color.RGBA) color.RGBA {
+	if c, ok := parseColor(s); ok {
+		return c
+	}
+	return fallback
+}
+
+// dim mixes a color halfway with the background, the way terminals draw
+// faint text such as soft-masked bases.
+func dim(c color.RGBA) color.RGBA {
+	mix := func(a, b uint8) uint8 { return uint8((uint16(a) + uint16(b)) / 2) }
+	return color.RGBA{mix(c.R, background.R), mix(c.G, background.G), mix(c.B, background.B), 0xff}
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/summary"
+)
+
+// SchemaVersion is the version of the JSON form of a region. It changes
+// when a field is removed or changes meaning, never when one is added.
+const SchemaVersion = 1
+
+// Region is the JSON form of a region: its bases, the stats of the stats
+// pane as numbers, and its tracks. Every coordinate is 1-based and
+// inclusive, like region strings such as "chr1:1,001-2,000".
+type Region struct {
+	SchemaVersion int     `json:"schema_version"`
+	File          string  `json:"file"`   // Name of the file
+	Region        string  `json:"region"` // As a string, e.g. "chr1:1001-2000"
+	Ref           string  `json:"ref"`
+	Start         int64   `json:"start"`
+	End           int64   `json:"end"`
+	Sequence      string  `json:"sequence"`
+	Stats         Stats   `json:"stats"`
+	Tracks        []Track `json:"tracks"` // Never null, so it can always be iterated
+}
+
+// Stats are the stats of a region. Percentages are of all its bases, N
+// included, as in the stats pane.
+type Stats struct {
+	Length        int64   `json:"length"`
+	GCCount       int64   `json:"gc_count"`
+	GCPercent     float64 `json:"gc_percent"`
+	NCount        int64   `json:"n_count"`
+	NRuns         int     `json:"n_runs"`
+	MaskedCount   int64   `json:"masked_count"`
+	MaskedPercent float64 `json:"masked_percent"`
+}
+
+// Track is a track over a region, such as its N runs.
+type Track struct {
+	Name      string     `json:"name"` // adapter.TrackGaps or adapter.TrackSoftMask
+	Intervals []Interval `json:"intervals"`
+}
+
+// Interval is a feature of a track, clipped to the region.
+type Interval struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// NewRegion returns the JSON form of a region of a file, with the tracks
+// of slice.
+func NewRegion(file string, reg adapter.Region, slice adapter.Slice) Region {
+	seq := slice.Sequence
+	c := summary.Count(seq)
+	gaps, _ := fasta.FindRuns(reg.Ref, seq, reg.Start-1, false)
+	stats := Stats{
+		Length:      c.Bases,
+		GCCount:     c.GC,
+		NCount:      c.N,
+		NRuns:       len(gaps),
+		MaskedCount: c.Masked,
+	}
+	if c.Bases > 0 {
+		stats.GCPercent = float64(c.GC) / float64(c.Bases) * 100
+		stats.MaskedPercent = float64(c.Masked) / float64(c.Bases) * 100
+	}
+
+	tracks := make([]Track, 0, len(slice.Tracks))
+	for _, t := range slice.Tracks {
+		track := Track{Name: t.Name, Intervals: make([]Interval, 0, len(t.Intervals))}
+		for _, iv := range t.Intervals {
+			// Intervals are 0-based and half-open.
+			start, end := max(iv.Start+1, reg.Start), min(iv.End, reg.End)
+			if start <= end {
+				track.Intervals = append(track.Intervals, Interval{Start: start, End: end})
+			}
+		}
+		tracks = append(tracks, track)
+	}
+
+	return Region{
+		SchemaVersion: SchemaVersion,
+		File:          file,
+		Region:        reg.String(),
+		Ref:           reg.Ref,
+		Start:         reg.Start,
+		End:           reg.End,
+		Sequence:      string(seq),
+		Stats:         stats,
+		Tracks:        tracks,
+	}
+}
+
+// WriteJSON writes a region to w as indented JSON.
+func WriteJSON(w io.Writer, r Region) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+func TestWriteJSON(t *testing.T) {
+	reg := adapter.Region{Ref: "chr1", Start: 11, End: 20}
+	slice := adapter.Slice{
+		Sequence: []byte("ACGNNacgtA"),
+		Tracks: []adapter.Track{
+			// Intervals are 0-based and half-open; the second starts before
+			// the region and is clipped to it.
+			{Name: adapter.TrackGaps, Intervals: []adapter.Interval{{Ref: "chr1", Start: 13, End: 15}}},
+			{Name: adapter.TrackSoftMask, Intervals: []adapter.Interval{{Ref: "chr1", Start: 0, End: 19}}},
+			{Name: "empty"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, NewRegion("test.fa", reg, slice)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The schema is stable: changing it breaks the notebooks that read it.
+	want := `{
+  "schema_version": 1,
+  "file": "test.fa",
+  "region": "chr1:11-20",
+  "ref": "chr1",
+  "start": 11,
+  "end": 20,
+  "sequence": "ACGNNacgtA",
+  "stats": {
+    "length": 10,
+    "gc_count": 4,
+    "gc_percent": 40,
+    "n_count": 2,
+    "n_runs": 1,
+    "masked_count": 4,
+    "masked_percent": 40
+  },
+  "tracks": [
+    {
+      "name": "gap",
+      "intervals": [
+        {
+          "start": 14,
+          "end": 15
+        }
+      ]
+    },
+    {
+      "name": "softmask",
+      "intervals": [
+        {
+          "start": 11,
+          "end": 19
+        }
+      ]
+    },
+    {
+      "name": "empty",
+      "intervals": []
+    }
+  ]
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected JSON:\n%s\nwant:\n%s", got, want)
+	}
+}
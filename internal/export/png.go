@@ -0,0 +1,265 @@
+// Package export writes what the viewer shows to files for reports and
+// notebooks, such as PNG figures and JSON.
+package export
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// MaxFigureBases is the longest region drawn in a figure. Beyond it, the
+// image would be too tall to be of use in a report.
+const MaxFigureBases = 20_000
+
+// DefaultColumns is the number of bases per row of a figure when none is
+// given.
+const DefaultColumns = 100
+
+const (
+	cellWidth      = 7  // Width of a character of basicfont.Face7x13
+	lineHeight     = 15 // Height of a line of text, with room between lines
+	margin         = 12 // Blank border around the figure
+	rulerTick      = 10 // Columns between tick marks, as in the viewer
+	rulerLabel     = 50 // Columns between tick labels
+	laneLabelWidth = 6  // Widest lane label, "masked"
+	statsKeyWidth  = 12 // Width of the stat names, as in the stats pane
+)
+
+// Colors of the terminal the figure imitates.
+var (
+	background = color.RGBA{0x1c, 0x1c, 0x1c, 0xff}
+	foreground = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+)
+
+// Figure is a region drawn as the viewer shows it: its bases in the colors
+// of a palette, under a ruler, with a lane for every track that covers part
+// of a row, and the stats of the region below.
+type Figure struct {
+	Title     string          // Drawn above the region, usually the file name
+	Region    adapter.Region  // Where Slice comes from
+	Slice     adapter.Slice   // Bases, stats and tracks; every track is drawn
+	Columns   int             // Bases per row; DefaultColumns when 0
+	Colors    map[byte]string // Color of each uppercase base, as in the theme
+	Theme     config.Theme    // Colors of the ruler, the title and the lanes
+	Uppercase bool            // Draw soft-masked bases in uppercase, still dimmed
+}
+
+// figureRow is a row of bases, with the lanes drawn under it.
+type figureRow struct {
+	start, end int // Offsets into the sequence
+	lanes      []figureLane
+}
+
+// figureLane marks the columns of a row covered by a track.
+type figureLane struct {
+	label string
+	runs  [][2]int // Column ranges, half-open
+}
+
+// WritePNG draws a figure and writes it to w as a PNG image.
+func WritePNG(w io.Writer, f Figure) error {
+	img, err := f.Draw()
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Draw draws a figure.
+func (f Figure) Draw() (*image.RGBA, error) {
+	seq := f.Slice.Sequence
+	if len(seq) == 0 {
+		return nil, fmt.Errorf("nothing to draw: %s is empty", f.Region)
+	}
+	if len(seq) > MaxFigureBases {
+		return nil, fmt.Errorf("%s is too long for a figure: %d bases, at most %d", f.Region, len(seq), MaxFigureBases)
+	}
+	columns := f.Columns
+	if columns <= 0 {
+		columns = DefaultColumns
+	}
+
+	// 1. Wrap the sequence into rows and find the lanes under each.
+	var rows []figureRow
+	for start := 0; start < len(seq); start += columns {
+		row := figureRow{start: start, end: min(start+columns, len(seq))}
+		from, to := f.Region.Start-1+int64(row.start), f.Region.Start-1+int64(row.end)
+		for _, track := range f.Slice.Tracks {
+			lane := figureLane{label: adapter.TrackLabel(track.Name)}
+			for _, iv := range track.Intervals {
+				if iv.End > from && iv.Start < to {
+					lane.runs = append(lane.runs, [2]int{int(max(iv.Start, from) - from), int(min(iv.End, to) - from)})
+				}
+			}
+			if len(lane.runs) > 0 {
+				row.lanes = append(row.lanes, lane)
+			}
+		}
+		rows = append(rows, row)
+	}
+	stats := slices.Sorted(func(yield func(string) bool) {
+		for k := range f.Slice.Stats {
+			if !yield(k) {
+				return
+			}
+		}
+	})
+
+	// 2. Size the image: the title, the ruler, the rows and their lanes,
+	// then the stats.
+	last := f.Region.Start - 1 + int64(len(seq))
+	marginCols := max(len(strconv.FormatInt(last, 10)), laneLabelWidth) + 1
+	subtitle := fmt.Sprintf("%s  %s", f.Region, formatLength(len(seq)))
+	widthCols := max(marginCols+columns, len(f.Title), len(subtitle))
+	lines := 3 + 2 // Title, subtitle, a blank line, and the ruler
+	for _, row := range rows {
+		lines += 1 + len(row.lanes)
+	}
+	if len(stats) > 0 {
+		lines += 1 + len(stats)
+		widthCols = max(widthCols, statsKeyWidth+maxValueWidth(f.Slice.Stats)+2)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, widthCols*cellWidth+2*margin, lines*lineHeight+2*margin))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	c := canvas{img: img}
+
+	// 3. Draw the title, and the ruler with ticks every 10 columns and
+	// labels every 50, in bases from the start of each row.
+	highlight := colorOr(f.Theme.Highlight, foreground)
+	ruler := colorOr(f.Theme.Border, foreground)
+	c.text(0, 0, f.Title, highlight)
+	c.text(0, 1, subtitle, foreground)
+	for col := rulerTick; col <= columns; col += rulerTick {
+		height := 3
+		if col%rulerLabel == 0 {
+			height = 7
+			label := strconv.Itoa(col)
+			c.text(marginCols+col-len(label), 3, label, ruler)
+		}
+		c.tick(marginCols+col-1, 4, height, ruler)
+	}
+
+	// 4. Draw the rows, each with its coordinate in the margin and its
+	// lanes below.
+	palette := f.palette()
+	lane := colorOr(f.Theme.Lane, foreground)
+	line := 5
+	for _, row := range rows {
+		c.text(0, line, strconv.FormatInt(f.Region.Start+int64(row.start), 10), foreground)
+		for i, base := range seq[row.start:row.end] {
+			ink := palette[base]
+			if f.Uppercase && base >= 'a' && base <= 'z' {
+				base -= 'a' - 'A'
+			}
+			c.text(marginCols+i, line, string(base), ink)
+		}
+		line++
+		for _, l := range row.lanes {
+			c.text(0, line, l.label, foreground)
+			for _, run := range l.runs {
+				c.bar(marginCols+run[0], marginCols+run[1], line, lane)
+			}
+			line++
+		}
+	}
+
+	// 5. Draw the stats, as in the stats pane.
+	line++
+	for _, k := range stats {
+		c.text(0, line, fmt.Sprintf("%-*s", statsKeyWidth, k), foreground)
+		c.text(statsKeyWidth, line, f.Slice.Stats[k], highlight)
+		line++
+	}
+	return img, nil
+}
+
+// palette returns the color of every byte: the color of its base, dimmed
+// for soft-masked lowercase bases, or else the foreground.
+func (f Figure) palette() [256]color.RGBA {
+	var p [256]color.RGBA
+	for i := range p {
+		p[i] = foreground
+	}
+	for base, s := range f.Colors {
+		if c, ok := parseColor(s); ok {
+			p[base] = c
+		}
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		p[c] = dim(p[c-'a'+'A'])
+	}
+	return p
+}
+
+// maxValueWidth returns the width of the longest stat value.
+func maxValueWidth(stats map[string]string) int {
+	width := 0
+	for _, v := range stats {
+		width = max(width, len(v))
+	}
+	return width
+}
+
+// formatLength formats a number of bases, e.g. "1,200 bp".
+func formatLength(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s + " bp"
+}
+
+// canvas draws on a grid of character cells inside the margin of an
+// image, like a terminal.
+type canvas struct {
+	img *image.RGBA
+}
+
+// cell returns the top left corner of a cell.
+func (c canvas) cell(col, line int) image.Point {
+	return image.Pt(margin+col*cellWidth, margin+line*lineHeight)
+}
+
+// text draws text from a cell.
+func (c canvas) text(col, line int, s string, ink color.Color) {
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	p := c.cell(col, line)
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(ink),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(p.X, p.Y+basicfont.Face7x13.Ascent+1),
+	}
+	d.DrawString(s)
+}
+
+// tick draws a ruler tick of a height in pixels at the middle of a cell,
+// rising from the bottom of its line.
+func (c canvas) tick(col, line, height int, ink color.Color) {
+	p := c.cell(col, line)
+	x, bottom := p.X+cellWidth/2, p.Y+lineHeight-2
+	draw.Draw(c.img, image.Rect(x, bottom-height, x+1, bottom), image.NewUniform(ink), image.Point{}, draw.Src)
+}
+
+// bar draws a lane bar across the cells from one column to another,
+// half-open, in the middle of a line.
+func (c canvas) bar(from, to, line int, ink color.Color) {
+	p, q := c.cell(from, line), c.cell(to, line)
+	mid := p.Y + lineHeight/2
+	draw.Draw(c.img, image.Rect(p.X, mid-2, q.X, mid+3), image.NewUniform(ink), image.Point{}, draw.Src)
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/config"
+)
+
+func TestWritePNG(t *testing.T) {
+	seq := []byte(strings.Repeat("ACGT", 30) + strings.Repeat("N", 20) + "acgt")
+	f := Figure{
+		Title:  "test.fa",
+		Region: adapter.Region{Ref: "chr1", Start: 1001, End: 1144},
+		Slice: adapter.Slice{
+			Sequence: seq,
+			Stats:    map[string]string{"Length": "144 bp"},
+			Tracks:   []adapter.Track{{Name: adapter.TrackGaps, Intervals: []adapter.Interval{{Ref: "chr1", Start: 1120, End: 1140}}}},
+		},
+		Columns: 50,
+		Colors:  map[byte]string{'A': "#00FF00", 'N': "244"},
+		Theme:   config.Theme{Lane: "#FFAF00"},
+	}
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows of 50 columns after a margin of 7, as wide as "masked". The
+	// title, a subtitle and a blank line, the 2 lines of the ruler, 3 rows
+	// and a lane under the last, then a blank line and a stat.
+	wantWidth, wantHeight := 57*cellWidth+2*margin, 11*lineHeight+2*margin
+	if b := img.Bounds(); b.Dx() != wantWidth || b.Dy() != wantHeight {
+		t.Errorf("expected a %dx%d image, got %dx%d", wantWidth, wantHeight, b.Dx(), b.Dy())
+	}
+	found := map[color.RGBA]bool{}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			found[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)] = true
+		}
+	}
+	for name, c := range map[string]color.RGBA{
+		"base A":         {0x00, 0xff, 0x00, 0xff},
+		"base N":         {0x80, 0x80, 0x80, 0xff},
+		"soft-masked a":  dim(color.RGBA{0x00, 0xff, 0x00, 0xff}),
+		"N-run lane":     {0xff, 0xaf, 0x00, 0xff},
+		"the background": background,
+	} {
+		if !found[c] {
+			t.Errorf("expected %s drawn in %v", name, c)
+		}
+	}
+
+	f.Slice.Sequence = make([]byte, MaxFigureBases+1)
+	if err := WritePNG(&buf, f); err == nil {
+		t.Error("expected an error for a region too long")
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]color.RGBA{
+		"#FF5FAF": {0xff, 0x5f, 0xaf, 0xff},
+		"#f5a":    {0xff, 0x55, 0xaa, 0xff},
+		"9":       {0xff, 0x00, 0x00, 0xff},
+		"205":     {0xff, 0x5f, 0xaf, 0xff},
+		"244":     {0x80, 0x80, 0x80, 0xff},
+	}
+	for s, want := range cases {
+		if got, ok := parseColor(s); !ok || got != want {
+			t.Errorf("parseColor(%q) = %v, %v, expected %v", s, got, ok, want)
+		}
+	}
+	for _, s := range []string{"", "256", "#12345", "red"} {
+		if _, ok := parseColor(s); ok {
+			t.Errorf("parseColor(%q): expected an invalid color", s)
+		}
+	}
+}
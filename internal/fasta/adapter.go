@@ -1,11 +1,20 @@
 package fasta
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/guillechuma/bio-tui/internal/adapter"
 )
 
+// Format registers the FASTA adapter for the usual FASTA extensions.
+var Format = adapter.Format{
+	Name:       "FASTA",
+	Extensions: []string{".fa", ".fasta", ".fas", ".fna", ".ffn", ".faa", ".frn", ".mpfa"},
+	Indexes:    []string{".fai"},
+	New:        func() adapter.Reader { return &FastaAdapter{} },
+}
+
 // FastaAdapter satisfies the adapter.Reader interface for FASTA files.
 // It uses an IndexedFastaReader to provide fast, random access.
 type FastaAdapter struct {
@@ -14,7 +23,7 @@ type FastaAdapter struct {
 
 // Open initializes the reader by loading the FASTA and its index.
 func (a *FastaAdapter) Open(spec adapter.OpenSpec) error {
-	r, err := NewIndexedReader(spec.Path) // Creates the local IndexedReader
+	r, err := NewIndexedReader(spec) // Creates the local IndexedReader
 	if err != nil {
 		return err
 	}
@@ -30,17 +39,20 @@ func (a *FastaAdapter) Close() error {
 	return nil
 }
 
-// Capabilities reports that this adapter can look up symbols and read regions.
+// Capabilities reports that this adapter can look up symbols, read regions
+// and compute N-run and soft-mask tracks.
 func (a *FastaAdapter) Capabilities() adapter.Capability {
-	return adapter.CapSymbols | adapter.CapRegions
+	return adapter.CapSymbols | adapter.CapRegions | adapter.CapTracks
 }
 
-// ListSymbols returns a slice of all sequence IDs and their lengths.
+// ListSymbols returns a slice of all sequence IDs and their lengths,
+// in the order they appear in the file.
 func (a *FastaAdapter) ListSymbols() ([]adapter.Symbol, error) {
-	symbols := make([]adapter.Symbol, 0, len(a.reader.Index)) // Array length in memory record length of index
-	for name, record := range a.reader.Index {
+	records := a.reader.Records()
+	symbols := make([]adapter.Symbol, 0, len(records))
+	for _, record := range records {
 		symbols = append(symbols, adapter.Symbol{
-			Name:   name,
+			Name:   record.Name,
 			Length: record.Length,
 		})
 	}
@@ -65,25 +77,38 @@ func (a *FastaAdapter) LookupSymbol(sym string) (adapter.Region, error) {
 	return reg, nil
 }
 
-// Region fetches the sequence data for a specific genomic region.
+// Region fetches the sequence data for a specific genomic region. Only the
+// bases of the region are read, which matters for remote files.
 func (a *FastaAdapter) Region(reg adapter.Region) (adapter.Slice, error) {
-	// First, we fetch the entire sequence record for the given reference (e.g., 'chr1').
-	record, err := a.reader.Fetch(reg.Ref)
-	if err != nil {
-		return adapter.Slice{}, err
+	rec, ok := a.reader.Index[reg.Ref]
+	if !ok {
+		return adapter.Slice{}, fmt.Errorf("sequence with id '%s' not found in index", reg.Ref)
+	}
+	if reg.Start < 1 || reg.End > rec.Length || reg.Start > reg.End {
+		return adapter.Slice{}, fmt.Errorf("invalid slice coordinates: start %d, end %d for sequence of length %d", reg.Start, reg.End, rec.Length)
 	}
 
-	// Then, we use the Slice method we already built on FastaRecord
-	// to extract the specific subsequence.
-	subsequence, err := record.Slice(int(reg.Start), int(reg.End))
-	if err != nil {
-		return adapter.Slice{}, err
+	var subsequence bytes.Buffer
+	subsequence.Grow(int(reg.End - reg.Start + 1))
+	if err := a.reader.FetchRange(reg.Ref, reg.Start-1, reg.End, &subsequence); err != nil {
+		return adapter.Slice{}, fmt.Errorf("failed to read sequence data for id '%s': %w", reg.Ref, err)
 	}
+	return SliceOf(reg, subsequence.Bytes()), nil
+}
 
+// Remote reports whether the file is read over HTTP.
+func (a *FastaAdapter) Remote() bool {
+	return a.reader.Remote()
+}
+
+// SliceOf returns the slice of a region given its bases: the stats of its
+// composition, and its N-run and soft-mask tracks.
+func SliceOf(reg adapter.Region, subsequence []byte) adapter.Slice {
 	// --- Calculate Stats for the Subsequence ---
 	stats := make(map[string]string)
 	gcCount := 0
 	nCount := 0
+	maskedCount := 0
 	for _, base := range subsequence {
 		switch base {
 		case 'G', 'g', 'C', 'c':
@@ -91,23 +116,37 @@ func (a *FastaAdapter) Region(reg adapter.Region) (adapter.Slice, error) {
 		case 'N', 'n':
 			nCount++
 		}
+		// Lowercase bases are soft-masked repeats.
+		if base >= 'a' && base <= 'z' {
+			maskedCount++
+		}
 	}
 
-	var gcPercent float64
+	var gcPercent, maskedPercent float64
 	if len(subsequence) > 0 {
 		gcPercent = float64(gcCount) / float64(len(subsequence)) * 100
+		maskedPercent = float64(maskedCount) / float64(len(subsequence)) * 100
 	}
 
 	stats["Length"] = fmt.Sprintf("%d bp", len(subsequence))
 	stats["GC Content"] = fmt.Sprintf("%.2f%%", gcPercent)
 	stats["N Count"] = fmt.Sprintf("%d", nCount)
+	stats["Masked"] = fmt.Sprintf("%.2f%%", maskedPercent)
+
+	// --- Build the N-run and soft-mask tracks for the subsequence ---
+	gaps, masked := FindRuns(reg.Ref, subsequence, reg.Start-1, true)
+	stats["N Runs"] = fmt.Sprintf("%d", len(gaps))
 
 	slice := adapter.Slice{
 		Sequence: subsequence,
 		Stats:    stats,
+		Tracks: []adapter.Track{
+			{Name: adapter.TrackGaps, Intervals: gaps},
+			{Name: adapter.TrackSoftMask, Intervals: masked},
+		},
 	}
 
-	return slice, nil
+	return slice
 }
 
 // IterRows is not applicable to FASTA files in a meaningful way,
@@ -115,3 +154,18 @@ func (a *FastaAdapter) Region(reg adapter.Region) (adapter.Slice, error) {
 func (a *FastaAdapter) IterRows(ch chan<- []string, stop <-chan struct{}) error {
 	return fmt.Errorf("IterRows is not supported by the FastaAdapter")
 }
+
+// Track scans a whole reference on disk and returns the intervals of the
+// named track, either adapter.TrackGaps or adapter.TrackSoftMask.
+func (a *FastaAdapter) Track(ref, name string) ([]adapter.Interval, error) {
+	switch name {
+	case adapter.TrackGaps:
+		gaps, _, err := a.reader.ScanRuns(ref, false)
+		return gaps, err
+	case adapter.TrackSoftMask:
+		_, masked, err := a.reader.ScanRuns(ref, true)
+		return masked, err
+	default:
+		return nil, fmt.Errorf("track '%s' is not supported by the FastaAdapter", name)
+	}
+}
@@ -0,0 +1,29 @@
+package fasta
+
+// complement maps every IUPAC nucleotide code to its complement, keeping case.
+// Bytes that are not nucleotide codes map to themselves.
+var complement = func() [256]byte {
+	var t [256]byte
+	for i := range t {
+		t[i] = byte(i)
+	}
+	pairs := []string{"AT", "CG", "UA", "RY", "KM", "SS", "WW", "BV", "DH", "NN"}
+	for _, p := range pairs {
+		a, b := p[0], p[1]
+		t[a], t[a+'a'-'A'] = b, b+'a'-'A'
+		if a != 'U' {
+			t[b], t[b+'a'-'A'] = a, a+'a'-'A'
+		}
+	}
+	return t
+}()
+
+// ReverseComplement returns the reverse complement of a nucleotide sequence.
+// Soft-masked (lowercase) bases stay lowercase.
+func ReverseComplement(seq []byte) []byte {
+	rc := make([]byte, len(seq))
+	for i, base := range seq {
+		rc[len(seq)-1-i] = complement[base]
+	}
+	return rc
+}
@@ -0,0 +1,150 @@
+package fasta
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/index"
+	"github.com/guillechuma/bio-tui/internal/state"
+)
+
+// loadIndex returns the index of a FASTA file and the path it was loaded
+// from or saved to, which is empty when it is kept in memory only.
+//
+// An explicit spec.Index is used, or built there if missing. Otherwise the
+// index next to the file is used, then one in the user cache. A missing
+// index is built and saved where spec.IndexLocation says, falling back to
+// the next place when a directory is read-only: next to the file, in the
+// cache, and last in memory. Building is reported to the notify and
+// progress functions of the spec, and stops if its context is cancelled.
+func loadIndex(spec adapter.OpenSpec) (map[string]index.FaiRecord, string, error) {
+	beside := spec.Path + ".fai"
+	cached, cacheErr := cachedIndexPath(spec.Path)
+
+	// 1. Use an index that exists.
+	candidates := []string{beside}
+	if cacheErr == nil {
+		candidates = append(candidates, cached)
+	}
+	if spec.Index != "" {
+		candidates = []string{spec.Index}
+	}
+	for i, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		idx, err := index.ParseFai(path)
+		if err != nil {
+			return nil, "", err
+		}
+		if i > 0 {
+			spec.Notify.Send(adapter.LevelInfo, "Using cached FASTA index %s", path)
+		}
+		return idx, path, nil
+	}
+
+	// 2. Build the index.
+	spec.Notify.Send(adapter.LevelInfo, "FASTA index not found. Building the index of %s...", spec.Path)
+	progress := func(p index.Progress) {
+		spec.Report(adapter.Progress{
+			Task:   "Building index",
+			Done:   p.Bytes,
+			Total:  p.Total,
+			Detail: fmt.Sprintf("%d sequences", p.Sequences),
+		})
+	}
+	records, err := index.ScanFai(spec.Ctx(), spec.Path, progress)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build FASTA index: %w", err)
+	}
+	idx := index.FaiMap(records)
+	reportMixedEndings(spec, records)
+
+	// 3. Save it to the first place that can be written to.
+	var targets []string
+	switch {
+	case spec.Index != "":
+		if err := index.WriteFai(spec.Index, records); err != nil {
+			return nil, "", fmt.Errorf("failed to save FASTA index: %w", err)
+		}
+		spec.Notify.Send(adapter.LevelInfo, "Built FASTA index %s", spec.Index)
+		return idx, spec.Index, nil
+	case spec.IndexLocation == adapter.IndexAuto:
+		targets = []string{beside, cached}
+	case spec.IndexLocation == adapter.IndexCache:
+		targets = []string{cached}
+	}
+	var errs []string
+	if cacheErr != nil {
+		errs = append(errs, cacheErr.Error())
+	}
+	for _, path := range targets {
+		if path == "" {
+			continue // No cache directory
+		}
+		if path == cached {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				errs = append(errs, fmt.Sprintf("could not create cache directory: %v", err))
+				continue
+			}
+		}
+		if err := index.WriteFai(path, records); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if len(errs) > 0 {
+			spec.Notify.Send(adapter.LevelInfo, "Built FASTA index %s, as it could not be saved next to the file: %v", path, strings.Join(errs, "; "))
+		} else {
+			spec.Notify.Send(adapter.LevelInfo, "Built FASTA index %s", path)
+		}
+		return idx, path, nil
+	}
+
+	if len(targets) == 0 {
+		spec.Notify.Send(adapter.LevelInfo, "Built the FASTA index of %s in memory", spec.Path)
+	} else {
+		spec.Notify.Send(adapter.LevelWarn, "The FASTA index of %s is kept in memory and will be rebuilt next time, as it could not be saved: %v", spec.Path, strings.Join(errs, "; "))
+	}
+	return idx, "", nil
+}
+
+// cachedIndexPath returns where the index of a FASTA file is kept in the
+// user cache. The name changes with the path, size and modification time
+// of the file, so a changed file gets a new index.
+func cachedIndexPath(fastaPath string) (string, error) {
+	dir, err := state.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	key, err := state.CacheKey(fastaPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fai", key+".fai"), nil
+}
+
+// maxListed is the most sequence names listed in a notice.
+const maxListed = 5
+
+// reportMixedEndings warns about the sequences whose lines end in both LF
+// and CRLF. They read fine here, but the .fai index cannot describe them,
+// so other tools may misread them.
+func reportMixedEndings(spec adapter.OpenSpec, records []index.FaiRecord) {
+	var names []string
+	for _, rec := range records {
+		if rec.MixedEndings {
+			names = append(names, rec.Name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	listed := strings.Join(names[:min(len(names), maxListed)], ", ")
+	if len(names) > maxListed {
+		listed += fmt.Sprintf(" and %d more", len(names)-maxListed)
+	}
+	spec.Notify.Send(adapter.LevelWarn, "Mixed LF and CRLF line endings in %s: %s. Other tools may misread these sequences with this index.", filepath.Base(spec.Path), listed)
+}
@@ -0,0 +1,75 @@
+package fasta
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+func writeFasta(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.fa")
+	if err := os.WriteFile(path, []byte(">chr1\nACGT\nAC\n>chr2\nGGGG\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadIndex_Locations(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	t.Run("explicit", func(t *testing.T) {
+		path := writeFasta(t)
+		explicit := filepath.Join(t.TempDir(), "other.fai")
+		idx, where, err := loadIndex(adapter.OpenSpec{Path: path, Index: explicit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if where != explicit || len(idx) != 2 {
+			t.Errorf("expected 2 sequences in %s, got %d in %q", explicit, len(idx), where)
+		}
+		if _, err := os.Stat(path + ".fai"); !os.IsNotExist(err) {
+			t.Errorf("no index should be written next to the file")
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		path := writeFasta(t)
+		_, where, err := loadIndex(adapter.OpenSpec{Path: path, IndexLocation: adapter.IndexCache})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(where, os.Getenv("XDG_CACHE_HOME")) {
+			t.Errorf("expected the index in the cache, got %q", where)
+		}
+
+		// The cached index is found again, whatever the location.
+		var notices []string
+		notify := func(_ adapter.Level, text string) { notices = append(notices, text) }
+		_, again, err := loadIndex(adapter.OpenSpec{Path: path, Notify: notify})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again != where || len(notices) != 1 || !strings.Contains(notices[0], "cached") {
+			t.Errorf("expected the cached index %s to be reported, got %q with %q", where, again, notices)
+		}
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		path := writeFasta(t)
+		idx, where, err := loadIndex(adapter.OpenSpec{Path: path, IndexLocation: adapter.IndexMemory})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if where != "" || idx["chr2"].Offset != 20 {
+			t.Errorf("expected an index in memory only, got %q with %+v", where, idx)
+		}
+		entries, _ := os.ReadDir(filepath.Dir(path))
+		if len(entries) != 1 {
+			t.Errorf("nothing should be written next to the file, got %d entries", len(entries))
+		}
+	})
+}
@@ -5,48 +5,82 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"sync"
 
+	"github.com/guillechuma/bio-tui/internal/adapter"
 	"github.com/guillechuma/bio-tui/internal/index"
+	"github.com/guillechuma/bio-tui/internal/remote"
 )
 
 // IndexedFastaReader manages access to a FASTA file using a .fai index.
 type IndexedReader struct {
-	file  *os.File                   // The open FASTA file handle
-	Index map[string]index.FaiRecord // The in-memory index, mapping sequence ID to its record
+	file      source                     // The open FASTA file, local or remote
+	Index     map[string]index.FaiRecord // The in-memory index, mapping sequence ID to its record
+	IndexPath string                     // Where the index is kept; empty when only in memory
+
+	uniform sync.Map // Sequence name to whether uniformLines holds for it
 }
 
-// NewIndexedReader creates a reader by opening a FASTA file and parsing its .fai index.
-func NewIndexedReader(fastaPath string) (*IndexedReader, error) {
-	indexPath := fastaPath + ".fai"
+// source is where the bytes of a FASTA file are read from: an *os.File, or a
+// *remote.File for a URL.
+type source interface {
+	io.ReaderAt
+	io.Closer
+}
 
-	// Check if the index file exists.
-	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
-		// if it does not exists, build it
-		fmt.Fprintf(os.Stderr, "[info] FASTA index not found. Building now at %s...\n", indexPath)
-		if err := index.BuildFai(fastaPath); err != nil {
-			return nil, fmt.Errorf("failed to build FASTA index: %w", err)
-		}
+// NewIndexedReader creates a reader by opening a FASTA file and loading its
+// .fai index, which is looked for and built as described in loadIndex. A
+// URL is read over HTTP, as described in openRemote.
+func NewIndexedReader(spec adapter.OpenSpec) (*IndexedReader, error) {
+	if remote.IsURL(spec.Path) {
+		return openRemote(spec)
 	}
-	// 1. Open the main FASTA file. Keep it open.
-	fastaFile, err := os.Open(fastaPath)
+
+	// 1. Load the index first: building it may take a while, and may be cancelled.
+	idx, indexPath, err := loadIndex(spec)
 	if err != nil {
-		return nil, fmt.Errorf("could not open fasta file: %w", err)
+		return nil, err
 	}
 
-	// 2. Open and parse the index file with ParseFai
-	idx, err := index.ParseFai(indexPath)
+	// 2. Open the main FASTA file. Keep it open.
+	fastaFile, err := os.Open(spec.Path)
 	if err != nil {
-		fastaFile.Close() // Clean up the already opened fasta file
-		return nil, err
+		return nil, fmt.Errorf("could not open fasta file: %w", err)
 	}
+
 	// Create the reader instance and return it
 	reader := &IndexedReader{
-		file:  fastaFile,
-		Index: idx,
+		file:      fastaFile,
+		Index:     idx,
+		IndexPath: indexPath,
 	}
 	return reader, nil
 }
 
+// Remote reports whether the file is read over HTTP, where every read is a
+// request.
+func (r *IndexedReader) Remote() bool {
+	_, ok := r.file.(*remote.File)
+	return ok
+}
+
+// Records returns the index records of the sequences, in the order they
+// appear in the file.
+func (r *IndexedReader) Records() []index.FaiRecord {
+	records := make([]index.FaiRecord, 0, len(r.Index))
+	for _, rec := range r.Index {
+		records = append(records, rec)
+	}
+	// Map iteration order is random; keep the file order so lists and
+	// exports are stable between runs.
+	sort.Slice(records, func(i, j int) bool { return records[i].Offset < records[j].Offset })
+	return records
+}
+
+// readBlock is the most bytes read at once when streaming a sequence.
+const readBlock = 1 << 20
+
 // Fetch retrieves a single FastaRecord by its ID.
 func (r *IndexedReader) Fetch(id string) (*FastaRecord, error) {
 	// 1. Look up the record in our in-memory index.
@@ -54,40 +88,15 @@ func (r *IndexedReader) Fetch(id string) (*FastaRecord, error) {
 	if !ok {
 		return nil, fmt.Errorf("sequence with id '%s' not found in index", id)
 	}
-	// 2. Seek to the start of the sequence data in the large FASTA file.
-	_, err := r.file.Seek(indexRecord.Offset, io.SeekStart)
-	if err != nil {
-		return nil, fmt.Errorf("failed to seek to offset for id '%s': %w", id, err)
-	}
 
-	// 3. Read the sequence data directly, stripping newlines on the fly.
-	// We know the total length, how many bases are on each line,
-	// and how many bytes each line takes up (including the newline).
+	// 2. Read the sequence data directly, stripping line endings on the fly.
 	var seqBuilder bytes.Buffer
-	basesToRead := indexRecord.Length
-	for basesToRead > 0 {
-		// Determine how many bases to read from the current line.
-		chunkSize := min(basesToRead, indexRecord.LineBases)
-
-		// Read the full line (base + newline) from the file.
-		lineBuffer := make([]byte, indexRecord.LineBytes)
-		bytesRead, err := r.file.Read(lineBuffer)
-		if err != nil && err != io.EOF {
-			return nil, fmt.Errorf("failed to read sequence data for id '%s': %w", id, err)
-		}
-
-		// Append only the bases (not the newline) to our sequence.
-		seqBuilder.Write(lineBuffer[:chunkSize])
-
-		// In case we hit EOF in the middle of a line.
-		if bytesRead < int(indexRecord.LineBytes) && err == io.EOF {
-			break
-		}
-
-		basesToRead -= chunkSize
+	seqBuilder.Grow(int(indexRecord.Length))
+	if err := r.readBases(indexRecord, &seqBuilder); err != nil {
+		return nil, fmt.Errorf("failed to read sequence data for id '%s': %w", id, err)
 	}
 
-	// 4. Manually construct the FastaRecord.
+	// 3. Manually construct the FastaRecord.
 	record := &FastaRecord{
 		ID:  indexRecord.Name,
 		Seq: seqBuilder.Bytes(),
@@ -97,6 +106,136 @@ func (r *IndexedReader) Fetch(id string) (*FastaRecord, error) {
 	return record, nil
 }
 
+// FetchRange writes the bases of a sequence from start to end, 0-based and
+// half-open, to w, without reading the rest of the sequence into memory. The
+// range is clipped to the sequence. When the lines of the sequence all end
+// the same way, reading starts right at start; otherwise the bases before
+// it are read and dropped, as line lengths in bytes vary.
+func (r *IndexedReader) FetchRange(id string, start, end int64, w io.Writer) error {
+	rec, ok := r.Index[id]
+	if !ok {
+		return fmt.Errorf("sequence with id '%s' not found in index", id)
+	}
+	start, end = max(start, 0), min(end, rec.Length)
+	if start >= end {
+		return nil
+	}
+	if rec.LineBases > 0 && !rec.MixedEndings && r.uniformLines(rec) {
+		rec.Offset += start/rec.LineBases*rec.LineBytes + start%rec.LineBases
+		rec.Length = end - start
+		return r.readBases(rec, w)
+	}
+	rec.Length = end
+	return r.readBases(rec, &skipWriter{w: w, skip: start})
+}
+
+// uniformLines reports whether the lines of a sequence all end the way its
+// index record says, so that the offset of a base can be computed. A .fai
+// file does not record mixed LF and CRLF endings, but they move the last
+// base away from where the record puts it: right before a line ending and
+// the next header or the end of the file. The answer is kept per sequence.
+func (r *IndexedReader) uniformLines(rec index.FaiRecord) bool {
+	if ok, found := r.uniform.Load(rec.Name); found {
+		return ok.(bool)
+	}
+	ok := false
+	if ending := rec.LineBytes - rec.LineBases; ending == 1 || ending == 2 {
+		newline := "\r\n"[2-ending:]
+		buf := make([]byte, 2+len(newline))
+		n, err := r.file.ReadAt(buf, rec.Offset+sequenceSpan(rec)-1)
+		if err == nil || err == io.EOF {
+			b := buf[:n]
+			if len(b) > 0 && !isSpace(b[0]) {
+				rest, found := bytes.CutPrefix(b[1:], []byte(newline))
+				ok = len(b) == 1 || found && (len(rest) == 0 || rest[0] == '>')
+			}
+		}
+	}
+	r.uniform.Store(rec.Name, ok)
+	return ok
+}
+
+// skipWriter drops the first skip bytes written to it.
+type skipWriter struct {
+	w    io.Writer
+	skip int64
+}
+
+func (s *skipWriter) Write(p []byte) (int, error) {
+	drop := min(s.skip, int64(len(p)))
+	s.skip -= drop
+	if _, err := s.w.Write(p[drop:]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// readBases streams the bases of a sequence to w, from its offset in the
+// index until its length is reached, skipping line endings and other
+// whitespace. Unlike reading whole lines of LineBytes, this copes with
+// lines that end in LF or CRLF, even both within a sequence. It stops early
+// at the next header or the end of the file, if the index is stale.
+// ReadAt keeps no shared file position, so reads can run concurrently.
+func (r *IndexedReader) readBases(rec index.FaiRecord, w io.Writer) error {
+	buf := make([]byte, min(readBlock, max(4096, sequenceSpan(rec))))
+	pos := rec.Offset
+	end := rec.Offset + sequenceSpan(rec) // Where the bases end, if the lines all end alike
+	remaining := rec.Length
+	lineStart := true
+	for remaining > 0 {
+		// Read no further than needed, as every byte of a remote file is
+		// downloaded.
+		n, err := r.file.ReadAt(buf[:min(int64(len(buf)), max(end-pos, 4096))], pos)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		pos += int64(n)
+
+		// Copy the runs of bases between the line endings.
+		chunk := buf[:n]
+		for len(chunk) > 0 && remaining > 0 {
+			if isSpace(chunk[0]) {
+				lineStart = chunk[0] == '\n'
+				chunk = chunk[1:]
+				continue
+			}
+			if lineStart && chunk[0] == '>' {
+				return nil // The next record
+			}
+			lineStart = false
+			end := 0
+			for end < len(chunk) && int64(end) < remaining && !isSpace(chunk[end]) {
+				end++
+			}
+			if _, err := w.Write(chunk[:end]); err != nil {
+				return err
+			}
+			remaining -= int64(end)
+			chunk = chunk[end:]
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+	return nil
+}
+
+// isSpace reports whether c is ASCII whitespace, which is not a base.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// sequenceSpan returns the number of bytes a sequence occupies on disk,
+// from its first base to its last, including the newlines in between, if
+// all its lines end the same way.
+func sequenceSpan(rec index.FaiRecord) int64 {
+	if rec.Length == 0 || rec.LineBases == 0 {
+		return 0
+	}
+	fullLines := (rec.Length - 1) / rec.LineBases
+	return fullLines*rec.LineBytes + (rec.Length - fullLines*rec.LineBases)
+}
+
 // Close closes the underlying FASTA file.
 func (r *IndexedReader) Close() error {
 	return r.file.Close()
@@ -0,0 +1,153 @@
+package fasta
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// openFixture opens a fixture with an index kept in memory, so nothing is
+// written to testdata, and returns it with the notices sent while opening.
+func openFixture(t *testing.T, name string) (*IndexedReader, []string) {
+	t.Helper()
+	var notices []string
+	notify := func(_ adapter.Level, text string) { notices = append(notices, text) }
+	r, err := NewIndexedReader(adapter.OpenSpec{Path: "testdata/" + name, Notify: notify, IndexLocation: adapter.IndexMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r, notices
+}
+
+func TestLineEndings_SameSequences(t *testing.T) {
+	lf, _ := openFixture(t, "lf.fa")
+	for _, name := range []string{"crlf.fa", "mixed.fa"} {
+		other, _ := openFixture(t, name)
+		for id := range lf.Index {
+			want, err := lf.Fetch(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := other.Fetch(id)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !bytes.Equal(got.Seq, want.Seq) {
+				t.Errorf("%s: %s: expected %s, got %q", name, id, want.Seq, got.Seq)
+			}
+
+			// Ranges across lines, within one, and past the end.
+			n := int64(len(want.Seq))
+			for _, r := range [][2]int64{{0, n}, {3, 17}, {11, 12}, {n - 2, n + 5}, {n + 1, n + 3}} {
+				var buf bytes.Buffer
+				if err := other.FetchRange(id, r[0], r[1], &buf); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				expected := want.Seq[min(r[0], n):min(r[1], n)]
+				if !bytes.Equal(buf.Bytes(), expected) {
+					t.Errorf("%s: %s %v: expected %s, got %s", name, id, r, expected, buf.Bytes())
+				}
+			}
+
+			wantGaps, wantMasked, _ := lf.ScanRuns(id, true)
+			gotGaps, gotMasked, err := other.ScanRuns(id, true)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !reflect.DeepEqual(gotGaps, wantGaps) || !reflect.DeepEqual(gotMasked, wantMasked) {
+				t.Errorf("%s: %s: runs differ: %v %v, expected %v %v", name, id, gotGaps, gotMasked, wantGaps, wantMasked)
+			}
+		}
+	}
+}
+
+// TestLineEndings_SavedIndex reopens files with the .fai saved on the first
+// open, which does not record mixed line endings.
+func TestLineEndings_SavedIndex(t *testing.T) {
+	for _, name := range []string{"lf.fa", "crlf.fa", "mixed.fa"} {
+		data, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for _, open := range []string{"built", "saved"} {
+			r, err := NewIndexedReader(adapter.OpenSpec{Path: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.IndexPath != path+".fai" {
+				t.Fatalf("%s: expected the index saved next to the file, got %q", name, r.IndexPath)
+			}
+			var buf bytes.Buffer
+			if err := r.FetchRange("chr2", 20, 23, &buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != "ACG" {
+				t.Errorf("%s, index %s: expected ACG, got %q", name, open, buf.String())
+			}
+			r.Close()
+		}
+	}
+}
+
+func TestLineEndings_Index(t *testing.T) {
+	crlf, notices := openFixture(t, "crlf.fa")
+	if rec := crlf.Index["chr2"]; rec.LineBases != 10 || rec.LineBytes != 12 {
+		t.Errorf("CRLF lines: expected 10 bases in 12 bytes, got %+v", rec)
+	}
+	for _, n := range notices {
+		if strings.Contains(n, "Mixed") {
+			t.Errorf("no mixed line endings expected, got %q", n)
+		}
+	}
+
+	mixed, notices := openFixture(t, "mixed.fa")
+	if !mixed.Index["chr2"].MixedEndings || mixed.Index["chr1"].MixedEndings || mixed.Index["chr3"].MixedEndings {
+		t.Errorf("only chr2 mixes line endings, got %+v", mixed.Index)
+	}
+	reported := slices.ContainsFunc(notices, func(n string) bool {
+		return strings.HasPrefix(n, "Mixed LF and CRLF line endings in mixed.fa: chr2.")
+	})
+	if !reported {
+		t.Errorf("expected mixed line endings to be reported, got %q", notices)
+	}
+}
+
+func TestLineEndings_Parser(t *testing.T) {
+	parse := func(name string) []*FastaRecord {
+		f, err := os.Open("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var records []*FastaRecord
+		p := NewParser(f)
+		for {
+			rec, err := p.Next()
+			if err == io.EOF {
+				return records
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			records = append(records, rec)
+		}
+	}
+	want := parse("lf.fa")
+	for _, name := range []string{"crlf.fa", "mixed.fa"} {
+		if got := parse(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: records differ from lf.fa", name)
+		}
+	}
+}
@@ -13,10 +13,16 @@ type Parser struct {
 	peekedLine string // Store the next header line we've already read
 }
 
+// maxLineLength is the longest line read, so that unwrapped chromosomes,
+// whose sequence is on a single line, can be parsed too.
+const maxLineLength = 1 << 30
+
 // NewParser creates a new FASTA parser
 func NewParser(r io.Reader) *Parser {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineLength)
 	return &Parser{
-		scanner: bufio.NewScanner(r),
+		scanner: scanner,
 	}
 }
 
@@ -28,3 +28,11 @@ func TestFastaRecord_Slice(t *testing.T) {
 	}
 
 }
+
+func TestReverseComplement(t *testing.T) {
+	actual := ReverseComplement([]byte("ACGTNacgtnRY"))
+	expected := []byte("RYnacgtNACGT")
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("ReverseComplement() failed: expected %s, got %s", expected, actual)
+	}
+}
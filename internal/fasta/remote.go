@@ -0,0 +1,49 @@
+package fasta
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/index"
+	"github.com/guillechuma/bio-tui/internal/remote"
+)
+
+// openRemote opens a FASTA file on a web server. Its index is spec.Index,
+// a URL or a local file, or else the .fai next to it on the server: it
+// cannot be built, since that would read the whole file.
+func openRemote(spec adapter.OpenSpec) (*IndexedReader, error) {
+	// 1. Download the index, which is small.
+	indexPath := spec.Index
+	if indexPath == "" {
+		indexPath = remote.Sidecar(spec.Path, ".fai")
+	}
+	var idx map[string]index.FaiRecord
+	if remote.IsURL(indexPath) {
+		data, err := remote.Get(spec.Ctx(), indexPath)
+		if errors.Is(err, fs.ErrNotExist) && spec.Index == "" {
+			return nil, fmt.Errorf("no FASTA index at %s: remote files are read with the .fai index next to them on the server, or one given with --index", indexPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not download FASTA index: %w", err)
+		}
+		if idx, err = index.ReadFai(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("could not read FASTA index %s: %w", indexPath, err)
+		}
+	} else {
+		var err error
+		if idx, err = index.ParseFai(indexPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. Check that the file can be read in ranges; bases are fetched as
+	// they are viewed.
+	f, err := remote.Open(spec.Ctx(), spec.Path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open fasta file: %w", err)
+	}
+	return &IndexedReader{file: f, Index: idx, IndexPath: indexPath}, nil
+}
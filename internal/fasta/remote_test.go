@@ -0,0 +1,57 @@
+package fasta
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/index"
+)
+
+func TestIndexedReader_Remote(t *testing.T) {
+	local, _ := openFixture(t, "lf.fa")
+	dir := t.TempDir()
+	content, err := os.ReadFile("testdata/lf.fa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lf.fa"), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+	spec := adapter.OpenSpec{Path: srv.URL + "/lf.fa"}
+
+	// Without a .fai on the server, nothing can be read.
+	if _, err := NewIndexedReader(spec); err == nil || !strings.Contains(err.Error(), "no FASTA index") {
+		t.Fatalf("expected a missing index error, got %v", err)
+	}
+
+	// With one, every sequence reads as from the local file.
+	if err := index.WriteFai(filepath.Join(dir, "lf.fa.fai"), local.Records()); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewIndexedReader(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.IndexPath != spec.Path+".fai" {
+		t.Errorf("expected the index of the server, got %s", r.IndexPath)
+	}
+	for id := range local.Index {
+		want, _ := local.Fetch(id)
+		got, err := r.Fetch(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Seq, want.Seq) {
+			t.Errorf("%s: expected %s, got %s", id, want.Seq, got.Seq)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package fasta
+
+import (
+	"fmt"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// RunScanner finds runs of N bases (assembly gaps) and, optionally, runs of
+// lowercase (soft-masked) bases in a stream of sequence bytes.
+// It implements io.Writer, so raw FASTA lines can be copied straight into it;
+// newline and carriage-return bytes are skipped and do not advance the position.
+type RunScanner struct {
+	ref       string
+	softMask  bool
+	pos       int64 // 0-based position of the next base
+	gapStart  int64 // Start of the open N-run, or -1
+	maskStart int64 // Start of the open soft-masked run, or -1
+	gaps      []adapter.Interval
+	masked    []adapter.Interval
+}
+
+// NewRunScanner creates a scanner for the reference ref whose first fed base
+// sits at the 0-based position start.
+func NewRunScanner(ref string, start int64, softMask bool) *RunScanner {
+	return &RunScanner{
+		ref:       ref,
+		softMask:  softMask,
+		pos:       start,
+		gapStart:  -1,
+		maskStart: -1,
+	}
+}
+
+// Write feeds the next chunk of sequence bytes to the scanner.
+func (s *RunScanner) Write(p []byte) (int, error) {
+	for _, base := range p {
+		switch base {
+		case '\n', '\r':
+			continue
+		}
+
+		isGap := base == 'N' || base == 'n'
+		if isGap && s.gapStart < 0 {
+			s.gapStart = s.pos
+		} else if !isGap && s.gapStart >= 0 {
+			s.gaps = append(s.gaps, s.interval(s.gapStart, adapter.TrackGaps))
+			s.gapStart = -1
+		}
+
+		if s.softMask {
+			isMasked := base >= 'a' && base <= 'z'
+			if isMasked && s.maskStart < 0 {
+				s.maskStart = s.pos
+			} else if !isMasked && s.maskStart >= 0 {
+				s.masked = append(s.masked, s.interval(s.maskStart, adapter.TrackSoftMask))
+				s.maskStart = -1
+			}
+		}
+		s.pos++
+	}
+	return len(p), nil
+}
+
+// Finish closes any open runs and returns the N-runs and soft-masked runs found.
+// The soft-masked slice is always nil when the scanner was created without softMask.
+func (s *RunScanner) Finish() (gaps, masked []adapter.Interval) {
+	if s.gapStart >= 0 {
+		s.gaps = append(s.gaps, s.interval(s.gapStart, adapter.TrackGaps))
+		s.gapStart = -1
+	}
+	if s.maskStart >= 0 {
+		s.masked = append(s.masked, s.interval(s.maskStart, adapter.TrackSoftMask))
+		s.maskStart = -1
+	}
+	return s.gaps, s.masked
+}
+
+// interval closes a run that started at start and ends at the current position.
+func (s *RunScanner) interval(start int64, name string) adapter.Interval {
+	return adapter.Interval{Ref: s.ref, Start: start, End: s.pos, Name: name}
+}
+
+// FindRuns scans an in-memory sequence whose first base sits at the 0-based
+// position offset on ref.
+func FindRuns(ref string, seq []byte, offset int64, softMask bool) (gaps, masked []adapter.Interval) {
+	s := NewRunScanner(ref, offset, softMask)
+	s.Write(seq)
+	return s.Finish()
+}
+
+// ScanRuns streams a whole sequence from disk through a RunScanner,
+// so N-runs can be located without loading the sequence into memory.
+func (r *IndexedReader) ScanRuns(id string, softMask bool) (gaps, masked []adapter.Interval, err error) {
+	indexRecord, ok := r.Index[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("sequence with id '%s' not found in index", id)
+	}
+
+	// Only the bases are fed to the scanner, whatever the line endings.
+	s := NewRunScanner(id, 0, softMask)
+	if err := r.readBases(indexRecord, s); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan sequence '%s': %w", id, err)
+	}
+	gaps, masked = s.Finish()
+	return gaps, masked, nil
+}
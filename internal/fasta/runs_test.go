@@ -0,0 +1,33 @@
+package fasta
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+func TestRunScanner_SplitAcrossLines(t *testing.T) {
+	// A gap and a soft-masked run that both span a line break.
+	scanner := NewRunScanner("chr1", 0, true)
+	scanner.Write([]byte("ACGTNN\n"))
+	scanner.Write([]byte("NNacgt\n"))
+	scanner.Write([]byte("ACnn"))
+	gaps, masked := scanner.Finish()
+
+	expectedGaps := []adapter.Interval{
+		{Ref: "chr1", Start: 4, End: 8, Name: adapter.TrackGaps},
+		{Ref: "chr1", Start: 14, End: 16, Name: adapter.TrackGaps},
+	}
+	expectedMasked := []adapter.Interval{
+		{Ref: "chr1", Start: 8, End: 12, Name: adapter.TrackSoftMask},
+		{Ref: "chr1", Start: 14, End: 16, Name: adapter.TrackSoftMask},
+	}
+
+	if !reflect.DeepEqual(gaps, expectedGaps) {
+		t.Errorf("gaps: expected %v, got %v", expectedGaps, gaps)
+	}
+	if !reflect.DeepEqual(masked, expectedMasked) {
+		t.Errorf("masked: expected %v, got %v", expectedMasked, masked)
+	}
+}
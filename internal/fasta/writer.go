@@ -0,0 +1,24 @@
+package fasta
+
+import (
+	"bufio"
+	"io"
+)
+
+// DefaultLineWidth is the number of bases per line used by samtools faidx.
+const DefaultLineWidth = 60
+
+// WriteRecord writes one FASTA record, wrapping the sequence every width
+// bases. A width of 0 or less writes the sequence on a single line.
+func WriteRecord(w io.Writer, header string, seq []byte, width int) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(">" + header + "\n")
+	if width <= 0 {
+		width = max(len(seq), 1)
+	}
+	for start := 0; start < len(seq); start += width {
+		bw.Write(seq[start:min(start+width, len(seq))])
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
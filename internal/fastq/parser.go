@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
 )
 
 // Parser reads FastqRecords from a reader.
@@ -11,10 +12,16 @@ type Parser struct {
 	scanner *bufio.Scanner
 }
 
+// maxLineLength is the longest line read, so that long reads, which can
+// span megabases, can be parsed too.
+const maxLineLength = 1 << 30
+
 // NewParser creates a new FASTQ parser
 func NewParser(r io.Reader) *Parser {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineLength)
 	return &Parser{
-		scanner: bufio.NewScanner(r),
+		scanner: scanner,
 	}
 }
 
@@ -22,14 +29,18 @@ func NewParser(r io.Reader) *Parser {
 func (p *Parser) Next() (*FastqRecord, error) {
 	// FASTQ record is always four lines.
 
-	// Read the first line (ID). If it fails, we might be at the end of the file.
-	if !p.scanner.Scan() {
-		if err := p.scanner.Err(); err != nil {
-			return nil, err
+	// Read the first line (ID), skipping blank lines between records. If it
+	// fails, we might be at the end of the file.
+	var idLine string
+	for idLine == "" {
+		if !p.scanner.Scan() {
+			if err := p.scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
 		}
-		return nil, io.EOF
+		idLine = p.scanner.Text()
 	}
-	idLine := p.scanner.Text()
 
 	// Read the next three lines (sequence, separator, quality).
 	if !p.scanner.Scan() {
@@ -48,10 +59,10 @@ func (p *Parser) Next() (*FastqRecord, error) {
 	qualLine := p.scanner.Text()
 
 	// Validation
-	if idLine[0] != '@' {
+	if !strings.HasPrefix(idLine, "@") {
 		return nil, fmt.Errorf("expected id line to start with '@', got '%s'", idLine)
 	}
-	if sepLine[0] != '+' {
+	if !strings.HasPrefix(sepLine, "+") {
 		return nil, fmt.Errorf("expected separator line to start with '+', got '%s'", sepLine)
 	}
 	if len(seqLine) != len(qualLine) {
@@ -2,13 +2,27 @@ package index
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+// progressStep is how many bytes BuildFai reads between progress reports.
+const progressStep = 4 << 20
+
+// Progress reports how far BuildFai has got through a FASTA file.
+type Progress struct {
+	Bytes     int64 // Bytes read so far
+	Total     int64 // Size of the file
+	Sequences int   // Sequences found so far
+}
+
 // FaiRecord holds the index information for a single sequence in a FASTA file.
 type FaiRecord struct {
 	Name      string // Name of the sequence
@@ -16,6 +30,34 @@ type FaiRecord struct {
 	Offset    int64  // Byte offset in the file where the sequence starts
 	LineBases int64  // Number of bases per line
 	LineBytes int64  // Number of bytes per line (including newline)
+
+	// MixedEndings is set by ScanFai on sequences whose lines end in both
+	// LF and CRLF, which the .fai layout cannot describe. It is not stored
+	// in .fai files.
+	MixedEndings bool
+}
+
+// lineEnding is a set of the ways lines end.
+type lineEnding uint8
+
+const (
+	endLF   lineEnding = 1 << iota // "\n"
+	endCRLF                        // "\r\n"
+)
+
+// mixed reports whether both LF and CRLF endings were seen.
+func (e lineEnding) mixed() bool { return e == endLF|endCRLF }
+
+// endingOf returns how a line ends, or nothing for the last line of a file
+// without a newline.
+func endingOf(line string) lineEnding {
+	switch {
+	case strings.HasSuffix(line, "\r\n"):
+		return endCRLF
+	case strings.HasSuffix(line, "\n"):
+		return endLF
+	}
+	return 0
 }
 
 // ParseFai reads a .fai file and returns a map of sequence names to their index records.
@@ -26,10 +68,15 @@ func ParseFai(path string) (map[string]FaiRecord, error) {
 		return nil, fmt.Errorf("could not open index file (.fai): %w", err)
 	}
 	defer indexFile.Close()
+	return ReadFai(indexFile)
+}
 
+// ReadFai reads a .fai index from r, such as one downloaded with the file
+// it indexes, like ParseFai.
+func ReadFai(r io.Reader) (map[string]FaiRecord, error) {
 	// Read the index line-by-line and store it in a map.
 	index := make(map[string]FaiRecord)
-	scanner := bufio.NewScanner(indexFile)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 		parts := strings.Split(line, "\t")
@@ -54,26 +101,92 @@ func ParseFai(path string) (map[string]FaiRecord, error) {
 	return index, scanner.Err()
 }
 
-// BuildFai creates a .fai index for a given FASTA file.
-// validating for consistent line lengths.
-func BuildFai(fastaPath string) error {
+// FaiMap maps index records by sequence name, like ParseFai.
+func FaiMap(records []FaiRecord) map[string]FaiRecord {
+	index := make(map[string]FaiRecord, len(records))
+	for _, rec := range records {
+		index[rec.Name] = rec
+	}
+	return index
+}
+
+// BuildFai creates a .fai index for a given FASTA file at indexPath,
+// usually fastaPath + ".fai". See ScanFai and WriteFai.
+func BuildFai(ctx context.Context, fastaPath, indexPath string, progress func(Progress)) error {
+	records, err := ScanFai(ctx, fastaPath, progress)
+	if err != nil {
+		return err
+	}
+	return WriteFai(indexPath, records)
+}
+
+// WriteFai writes index records to a .fai file. The index is written to a
+// temporary file first, so a failed write leaves no partial index behind.
+func WriteFai(indexPath string, records []FaiRecord) (err error) {
+	outFile, err := os.CreateTemp(filepath.Dir(indexPath), filepath.Base(indexPath)+".*.tmp")
+	if err != nil {
+		// Name the index rather than the temporary file.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return fmt.Errorf("could not create %s: %w", indexPath, err)
+	}
+	defer func() {
+		if cerr := outFile.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("could not write .fai file: %w", cerr)
+		}
+		if err == nil {
+			err = os.Rename(outFile.Name(), indexPath)
+		}
+		if err != nil {
+			os.Remove(outFile.Name())
+		}
+	}()
+	if err := outFile.Chmod(0o644); err != nil {
+		return fmt.Errorf("could not create .fai file: %w", err)
+	}
+
+	out := bufio.NewWriter(outFile)
+	for _, rec := range records {
+		fmt.Fprintf(out, "%s\t%d\t%d\t%d\t%d\n",
+			rec.Name, rec.Length, rec.Offset, rec.LineBases, rec.LineBytes)
+	}
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("could not write .fai file: %w", err)
+	}
+	return nil
+}
+
+// scanSerial reads a FASTA file line by line and returns its index records
+// in file order, validating for consistent line lengths. It is the
+// reference for the parallel scan, which falls back on it to report
+// errors.
+// Progress is reported at the start, every few megabytes and once done,
+// when progress is not nil. The scan stops when ctx is cancelled.
+func scanSerial(ctx context.Context, fastaPath string, progress func(Progress)) ([]FaiRecord, error) {
 	// Open the input fasta
 	inFile, err := os.Open(fastaPath)
 	if err != nil {
-		return fmt.Errorf("could not open fasta file to build index: %w", err)
+		return nil, fmt.Errorf("could not open fasta file to build index: %w", err)
 	}
 	defer inFile.Close()
-
-	// Create the output .fai file.
-	outFile, err := os.Create(fastaPath + ".fai")
+	info, err := inFile.Stat()
 	if err != nil {
-		return fmt.Errorf("could not create .fai file: %w", err)
+		return nil, fmt.Errorf("could not open fasta file to build index: %w", err)
+	}
+	report := func(p Progress) {
+		if progress != nil {
+			progress(p)
+		}
 	}
-	defer outFile.Close()
+	var records []FaiRecord
 
 	// use a bufio.Reader for line-by-line reading with offset tracking.
 	reader := bufio.NewReader(inFile)
 	var byteOffset int64 = 0
+	var sequences int
+	var nextReport int64 // Report once at the start
 
 	// State variables for the current sequence record
 	var current struct {
@@ -83,9 +196,33 @@ func BuildFai(fastaPath string) error {
 		lineBases              int64
 		lineBytes              int64
 		isShortLineEncountered bool // <-- New flag to track state
+		started                bool // A header was seen; its name may be empty
+		endings                lineEnding
+	}
+	addRecord := func() {
+		if current.started {
+			records = append(records, FaiRecord{
+				Name:      current.name,
+				Length:    current.length,
+				Offset:    current.offset,
+				LineBases: current.lineBases,
+				LineBytes: current.lineBytes,
+
+				MixedEndings: current.endings.mixed(),
+			})
+		}
 	}
 
 	for {
+		// Report progress and check for cancellation every few megabytes.
+		if byteOffset >= nextReport {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			report(Progress{Bytes: byteOffset, Total: info.Size(), Sequences: sequences})
+			nextReport = byteOffset + progressStep
+		}
+
 		// Read one line, keeping track of how many bytes were consumed.
 		line, err := reader.ReadString('\n')
 		lineBytesRead := int64(len(line))
@@ -93,27 +230,27 @@ func BuildFai(fastaPath string) error {
 		trimmedLine := strings.TrimSpace(line)
 
 		if err != nil && err != io.EOF {
-			return err // Handle unexpected errors
+			return nil, err // Handle unexpected errors
 		}
 
 		// Check if it's a header line.
 		if strings.HasPrefix(trimmedLine, ">") {
-			// If we were already tracking a sequence, we must write its entry first.
-			if current.name != "" {
-				fmt.Fprintf(outFile, "%s\t%d\t%d\t%d\t%d\n",
-					current.name, current.length, current.offset, current.lineBases, current.lineBytes)
-			}
+			// If we were already tracking a sequence, we must record its entry first.
+			addRecord()
 			// Start a new record.
-			current.name = strings.TrimSpace(trimmedLine[1:])
+			sequences++
+			current.started = true
+			current.name = seqName(trimmedLine)
 			current.length = 0
 			current.lineBases = 0
 			current.offset = byteOffset + lineBytesRead // The first base is on the *next* line
 			current.isShortLineEncountered = false      // Reset the flag.
-		} else if current.name != "" && len(trimmedLine) > 0 {
+			current.endings = 0
+		} else if current.started && len(trimmedLine) > 0 {
 			// This is a sequence line.
 			// **VALIDATION 1: Check if we've already seen a short line.**
 			if current.isShortLineEncountered {
-				return fmt.Errorf("format error in sequence '%s': unexpected sequence data after a short line", current.name)
+				return nil, fmt.Errorf("format error in sequence '%s': unexpected sequence data after a short line", current.name)
 			}
 
 			if current.lineBases == 0 {
@@ -125,7 +262,7 @@ func BuildFai(fastaPath string) error {
 				if int64(len(trimmedLine)) != current.lineBases {
 					// This line is shorter than the standard. It must be the last one.
 					if int64(len(trimmedLine)) > current.lineBases {
-						return fmt.Errorf("different line length in sequence '%s'", current.name)
+						return nil, fmt.Errorf("different line length in sequence '%s'", current.name)
 					}
 					// It's a short line. Set the flag.
 					current.isShortLineEncountered = true
@@ -133,6 +270,7 @@ func BuildFai(fastaPath string) error {
 			}
 			// This is a sequence line for the current record.
 			current.length += int64(len(trimmedLine))
+			current.endings |= endingOf(line)
 		}
 
 		byteOffset += lineBytesRead
@@ -140,11 +278,19 @@ func BuildFai(fastaPath string) error {
 			break
 		}
 	}
-	// Write the very last record after the loop finishes.
-	if current.name != "" {
-		fmt.Fprintf(outFile, "%s\t%d\t%d\t%d\t%d\n",
-			current.name, current.length, current.offset, current.lineBases, current.lineBytes)
-	}
+	// Record the very last sequence after the loop finishes.
+	addRecord()
+	report(Progress{Bytes: byteOffset, Total: info.Size(), Sequences: sequences})
+	return records, nil
+}
 
-	return nil
+// seqName returns the name of a sequence from its header line: the text
+// after '>' up to the first whitespace, as samtools does. The rest of the
+// line is a description, which is not part of the name.
+func seqName(header string) string {
+	name := header[1:]
+	if i := strings.IndexAny(name, " \t\r\n\v\f"); i >= 0 {
+		name = name[:i]
+	}
+	return name
 }
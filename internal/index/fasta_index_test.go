@@ -0,0 +1,60 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFasta(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.fa")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBuildFai_Progress(t *testing.T) {
+	path := writeFasta(t, ">chr1\nACGT\nAC\n>chr2\nGGGG\n")
+
+	var last Progress
+	if err := BuildFai(context.Background(), path, path+".fai", func(p Progress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Progress{Bytes: 25, Total: 25, Sequences: 2}
+	if last != expected {
+		t.Errorf("final progress: expected %+v, got %+v", expected, last)
+	}
+	fai, err := os.ReadFile(path + ".fai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(fai), "chr1\t6\t6\t4\t5\nchr2\t4\t20\t4\t5\n"; got != want {
+		t.Errorf("index: expected %q, got %q", want, got)
+	}
+}
+
+func TestBuildFai_Cancelled(t *testing.T) {
+	path := writeFasta(t, ">chr1\nACGT\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := BuildFai(ctx, path, path+".fai", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// Neither the index nor its temporary file is left behind.
+	if _, err := os.Stat(path + ".fai"); !os.IsNotExist(err) {
+		t.Errorf("expected no index, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".tmp" {
+			t.Errorf("temporary file left behind: %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package index
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// chunkSize is the number of bytes scanned by each task of the parallel
+// scan. Chunks are read in a streaming way, so memory stays at a buffer
+// per worker whatever the chunk size.
+const chunkSize = 16 << 20
+
+// ScanFai reads a FASTA file and returns its index records in file order,
+// validating for consistent line lengths, like samtools faidx.
+// The file is split into chunks that are scanned concurrently, each up to
+// the first line starting past its end, and the pieces of records found
+// in each chunk are stitched together in order.
+// Progress is reported at the start, as chunks are done and once done,
+// when progress is not nil. The scan stops when ctx is cancelled.
+func ScanFai(ctx context.Context, fastaPath string, progress func(Progress)) ([]FaiRecord, error) {
+	return scanParallel(ctx, fastaPath, chunkSize, runtime.GOMAXPROCS(0), progress)
+}
+
+// piece is the part of a record found in a chunk: its header, if the
+// header is in the chunk, and a summary of its sequence lines there.
+type piece struct {
+	header bool   // The piece starts with the header of the record
+	name   string // Name of the record, when the header is in the piece
+	offset int64  // Offset of the first base, when the header is in the piece
+	layout
+}
+
+// layout summarizes the sequence lines of a record, or of part of it.
+// Blank lines are skipped, as by scanSerial.
+type layout struct {
+	lines      int64 // Number of sequence lines
+	length     int64 // Number of bases
+	firstBases int64 // Bases on the first line
+	firstBytes int64 // Bytes of the first line, newline included
+	lastBases  int64 // Bases on the last line
+	uniform    bool  // Every line but the last has firstBases bases
+	endings    lineEnding
+}
+
+// addLine adds a sequence line at the end of the layout.
+func (l *layout) addLine(bases, bytes int64, ending lineEnding) {
+	l.append(layout{lines: 1, length: bases, firstBases: bases, firstBytes: bytes, lastBases: bases, uniform: true, endings: ending})
+}
+
+// append adds the lines of next at the end of the layout.
+func (l *layout) append(next layout) {
+	switch {
+	case next.lines == 0:
+		return
+	case l.lines == 0:
+		*l = next
+		return
+	}
+	// The last line of l and the first lines of next become middle lines,
+	// which must all have as many bases as the first line.
+	l.uniform = l.uniform && l.lastBases == l.firstBases &&
+		(next.lines == 1 || next.uniform && next.firstBases == l.firstBases)
+	l.lines += next.lines
+	l.length += next.length
+	l.lastBases = next.lastBases
+	l.endings |= next.endings
+}
+
+// valid reports whether the lines follow the FASTA layout: all lines have
+// the same length, but the last one which may be shorter.
+func (l layout) valid() bool {
+	return l.lines == 0 || l.uniform && l.lastBases <= l.firstBases
+}
+
+// chunkResult is the outcome of scanning a chunk.
+type chunkResult struct {
+	index  int
+	pieces []piece // The first one continues the record of the previous chunk
+	err    error
+}
+
+// scanParallel scans a FASTA file in chunks of the given size with the
+// given number of workers. See ScanFai.
+func scanParallel(ctx context.Context, fastaPath string, size int64, workers int, progress func(Progress)) ([]FaiRecord, error) {
+	f, err := os.Open(fastaPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open fasta file to build index: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not open fasta file to build index: %w", err)
+	}
+	total := info.Size()
+	report := func(p Progress) {
+		if progress != nil {
+			progress(p)
+		}
+	}
+	report(Progress{Total: total})
+
+	// 1. Scan the chunks concurrently. Results are buffered, so workers
+	// never wait for the collector, even when it gave up.
+	chunks := int((total + size - 1) / size)
+	tasks := make(chan int, chunks)
+	results := make(chan chunkResult, chunks)
+	for i := range chunks {
+		tasks <- i
+	}
+	close(tasks)
+	var wg sync.WaitGroup
+	for range min(workers, chunks) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				if ctx.Err() != nil {
+					return
+				}
+				start := int64(i) * size
+				pieces, err := scanChunk(f, start, min(start+size, total), total)
+				results <- chunkResult{index: i, pieces: pieces, err: err}
+			}
+		}()
+	}
+	defer wg.Wait()
+
+	// 2. Collect the chunks in any order, reporting progress as they come.
+	scanned := make([][]piece, chunks)
+	var done int64
+	var sequences int
+	for range chunks {
+		var res chunkResult
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case res = <-results:
+		}
+		if res.err != nil {
+			return nil, fmt.Errorf("could not read fasta file: %w", res.err)
+		}
+		scanned[res.index] = res.pieces
+		done += min(size, total-int64(res.index)*size)
+		for _, p := range res.pieces {
+			if p.header {
+				sequences++
+			}
+		}
+		report(Progress{Bytes: done, Total: total, Sequences: sequences})
+	}
+
+	// 3. Stitch the pieces together in file order. Lines before the first
+	// header belong to no record and are skipped.
+	var records []FaiRecord
+	var current *piece
+	for _, pieces := range scanned {
+		for _, p := range pieces {
+			if p.header {
+				if current != nil {
+					records = append(records, current.record())
+				}
+				current = &p
+			} else if current != nil {
+				current.append(p.layout)
+			}
+			if current != nil && !current.valid() {
+				// Let the line by line scan tell what is wrong and where.
+				return scanSerial(ctx, fastaPath, nil)
+			}
+		}
+	}
+	if current != nil {
+		records = append(records, current.record())
+	}
+	return records, nil
+}
+
+// record returns the index record of a complete record.
+func (p piece) record() FaiRecord {
+	return FaiRecord{
+		Name:      p.name,
+		Length:    p.length,
+		Offset:    p.offset,
+		LineBases: p.firstBases,
+		LineBytes: p.firstBytes,
+
+		MixedEndings: p.endings.mixed(),
+	}
+}
+
+// scanChunk scans the lines that start in [start, end) of a FASTA file of
+// the given size, reading the last of them up to its end.
+func scanChunk(f io.ReaderAt, start, end, size int64) ([]piece, error) {
+	// Find the first line that starts in the chunk: the one after the
+	// newline before the chunk, if the chunk does not start a line.
+	pos := start
+	if start > 0 {
+		pos = start - 1
+	}
+	r := bufio.NewReaderSize(io.NewSectionReader(f, pos, size-pos), int(min(1<<20, max(4096, end-start))))
+	if start > 0 {
+		skipped, err := skipLine(r)
+		if err == io.EOF {
+			return []piece{{}}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		pos += skipped
+	}
+
+	pieces := []piece{{}}
+	current := &pieces[0]
+	var line lineScan
+	for pos < end {
+		err := line.read(r)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		switch {
+		case line.header != nil:
+			pieces = append(pieces, piece{header: true, name: seqName(string(line.header)), offset: pos + line.bytes})
+			current = &pieces[len(pieces)-1]
+		case line.bases() > 0:
+			current.addLine(line.bases(), line.bytes, line.ending)
+		}
+		pos += line.bytes
+		if err == io.EOF {
+			break
+		}
+	}
+	return pieces, nil
+}
+
+// skipLine reads up to the end of the line, returning how many bytes it
+// took, newline included.
+func skipLine(r *bufio.Reader) (int64, error) {
+	var n int64
+	for {
+		frag, err := r.ReadSlice('\n')
+		n += int64(len(frag))
+		if err != bufio.ErrBufferFull {
+			return n, err
+		}
+	}
+}
+
+// lineScan reads a line in fragments, so that unwrapped sequences of any
+// length can be read with a fixed buffer. It keeps the header lines, and
+// only where the bases start and end on sequence lines.
+type lineScan struct {
+	bytes  int64  // Bytes of the line, newline included
+	first  int64  // Offset of the first non-space byte, or -1
+	last   int64  // Offset of the last non-space byte
+	header []byte // The line from its first non-space byte, if that is '>'
+	ending lineEnding
+	prev   byte // Byte before the last one read, to find CRLF across fragments
+}
+
+// read reads the next line. At the end of the file, it returns io.EOF
+// with the last line, which may be empty.
+func (l *lineScan) read(r *bufio.Reader) error {
+	*l = lineScan{first: -1, header: l.header[:0]}
+	isHeader := false
+	for {
+		frag, err := r.ReadSlice('\n')
+		if i := firstNonSpace(frag); i >= 0 {
+			if l.first < 0 {
+				l.first = l.bytes + int64(i)
+				isHeader = frag[i] == '>'
+				frag := frag[i:]
+				if isHeader {
+					l.header = append(l.header, frag...)
+				}
+			} else if isHeader {
+				l.header = append(l.header, frag...)
+			}
+			l.last = l.bytes + int64(lastNonSpace(frag))
+		}
+		l.bytes += int64(len(frag))
+		if err != bufio.ErrBufferFull {
+			if !isHeader {
+				l.header = nil
+			}
+			l.ending = lineEndingOf(frag, l.prev)
+			return err
+		}
+		l.prev = frag[len(frag)-1]
+	}
+}
+
+// lineEndingOf returns how a line ends, given its last fragment and the
+// byte before that fragment.
+func lineEndingOf(frag []byte, prev byte) lineEnding {
+	n := len(frag)
+	switch {
+	case n == 0 || frag[n-1] != '\n':
+		return 0
+	case n >= 2 && frag[n-2] == '\r', n == 1 && prev == '\r':
+		return endCRLF
+	}
+	return endLF
+}
+
+// bases returns the number of bases on a sequence line: its length without
+// the surrounding whitespace, as scanSerial counts them.
+func (l lineScan) bases() int64 {
+	if l.first < 0 {
+		return 0
+	}
+	return l.last - l.first + 1
+}
+
+// isSpace reports whether c is ASCII whitespace, as trimmed by
+// strings.TrimSpace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// firstNonSpace returns the index of the first non-space byte of b, or -1.
+func firstNonSpace(b []byte) int {
+	for i, c := range b {
+		if !isSpace(c) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastNonSpace returns the index of the last non-space byte of b, or -1.
+func lastNonSpace(b []byte) int {
+	for i := len(b) - 1; i >= 0; i-- {
+		if !isSpace(b[i]) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,149 @@
+package index
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// writeRandomFasta writes a FASTA file with sequences of random lengths and
+// line widths, some unwrapped, some empty, with blank lines and
+// descriptions, and returns its path. Depending on the seed, lines end in
+// LF, in CRLF, or in either at random.
+func writeRandomFasta(tb testing.TB, seed int64, sequences int, maxLength int) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), fmt.Sprintf("random%d.fa", seed))
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	rng := rand.New(rand.NewSource(seed))
+	newline := func() string {
+		switch {
+		case seed%3 == 1, seed%3 == 2 && rng.Intn(2) == 0:
+			return "\r\n"
+		}
+		return "\n"
+	}
+	for i := range sequences {
+		fmt.Fprintf(w, ">seq%d description %d%s", i, rng.Intn(100), newline())
+		length := rng.Intn(maxLength + 1)
+		width := []int{60, 70, 80, max(1, length)}[rng.Intn(4)]
+		for pos := 0; pos < length; pos += width {
+			for range min(width, length-pos) {
+				w.WriteByte("ACGTNacgt"[rng.Intn(9)])
+			}
+			w.WriteString(newline())
+		}
+		if rng.Intn(5) == 0 {
+			w.WriteString(newline()) // Blank line between records
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestScanParallel_MatchesSerial(t *testing.T) {
+	ctx := context.Background()
+	for seed := range int64(6) {
+		path := writeRandomFasta(t, seed, 50, 2000)
+		expected, err := scanSerial(ctx, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mixed := slices.ContainsFunc(expected, func(r FaiRecord) bool { return r.MixedEndings })
+		if mixed != (seed%3 == 2) {
+			t.Errorf("seed %d: mixed line endings found: %v", seed, mixed)
+		}
+		// Chunks smaller than a line, than a record and than the file.
+		for _, size := range []int64{7, 64, 1000, 1 << 20} {
+			got, err := scanParallel(ctx, path, size, 4, nil)
+			if err != nil {
+				t.Fatalf("seed %d, chunk size %d: %v", seed, size, err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("seed %d, chunk size %d: records differ from the serial scan", seed, size)
+			}
+		}
+	}
+}
+
+func TestScanParallel_Errors(t *testing.T) {
+	cases := map[string]string{
+		"longer line":          ">chr1\nACGT\nACGTA\n",
+		"line after short one": ">chr1\nACGT\nAC\nACGT\n",
+		"short middle line":    ">chr1\nACGT\nAC\nACGT\nACGT\n",
+	}
+	for name, content := range cases {
+		path := writeFasta(t, content)
+		_, want := scanSerial(context.Background(), path, nil)
+		for _, size := range []int64{3, 1 << 20} {
+			_, err := scanParallel(context.Background(), path, size, 2, nil)
+			if err == nil || err.Error() != want.Error() {
+				t.Errorf("%s, chunk size %d: expected %v, got %v", name, size, want, err)
+			}
+		}
+	}
+}
+
+func TestScanFai_SamtoolsOutput(t *testing.T) {
+	// samtools.fa.fai is what samtools faidx writes for samtools.fa: names
+	// stop at the first whitespace.
+	src, err := os.ReadFile("testdata/samtools.fa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("testdata/samtools.fa.fai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeFasta(t, string(src))
+	if err := BuildFai(context.Background(), path, path+".fai", nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path + ".fai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("index differs from samtools:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// benchmarkFasta is shared by the benchmarks, so both scan the same file.
+func benchmarkFasta(b *testing.B) string {
+	b.Helper()
+	return writeRandomFasta(b, 1, 200, 600_000) // About 60 MiB
+}
+
+func BenchmarkScanFai(b *testing.B) {
+	path := benchmarkFasta(b)
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	scans := map[string]func(context.Context, string, func(Progress)) ([]FaiRecord, error){
+		"serial":   scanSerial,
+		"parallel": ScanFai,
+	}
+	for _, name := range []string{"serial", "parallel"} {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(info.Size())
+			for b.Loop() {
+				if _, err := scans[name](context.Background(), path, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Package remote reads files on a web server with HTTP range requests, so
+// that indexed files can be browsed without downloading them first.
+package remote
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	blockSize = 256 << 10 // Bytes fetched at least per request
+	maxBlocks = 64        // Blocks kept in the cache, 16 MiB in all
+
+	fetchTimeout = time.Minute // Longest a request for blocks may take
+)
+
+// client makes the requests. It is a variable so that tests can replace it.
+var client = http.DefaultClient
+
+// IsURL reports whether a path is an HTTP or HTTPS URL rather than a file.
+func IsURL(p string) bool {
+	p = strings.ToLower(p)
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// Base returns the last element of the path of a URL, without its query,
+// like filepath.Base does for files.
+func Base(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return rawURL
+	}
+	return path.Base(u.Path)
+}
+
+// Sidecar returns the URL of a file kept next to another one, whose name
+// adds ext to it, such as the .fai index of a FASTA file. The query of the
+// URL, which often carries a token, is kept.
+func Sidecar(rawURL, ext string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL + ext
+	}
+	u.Path += ext
+	u.RawPath = ""
+	return u.String()
+}
+
+// Get downloads a whole file, such as an index. A file that the server
+// does not have is reported with an error that matches fs.ErrNotExist.
+func Get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(rawURL, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not download %s: %w", rawURL, err)
+	}
+	return data, nil
+}
+
+// statusError describes an unexpected response. Missing files match
+// fs.ErrNotExist, like local ones.
+func statusError(rawURL string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("%s: %w", rawURL, fs.ErrNotExist)
+	}
+	return fmt.Errorf("%s: server replied %s", rawURL, resp.Status)
+}
+
+// File reads a file on a web server. It satisfies io.ReaderAt with range
+// requests, keeping the blocks read last in a cache, since viewers read
+// the same region over and over as they scroll. It is safe for concurrent
+// use.
+type File struct {
+	url    string
+	size   int64
+	ctx    context.Context // Requests for blocks, cancelled by Close
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	blocks map[int64]*list.Element // Cached blocks by number, in lru
+	lru    *list.List              // Cached blocks, most recently used first
+}
+
+// block is a cached block of a file.
+type block struct {
+	n    int64
+	data []byte
+}
+
+// Open checks that the server has the file and answers range requests, and
+// learns its size. Nothing else is read until ReadAt.
+func Open(ctx context.Context, rawURL string) (*File, error) {
+	// Asking for the first byte tells both the size and whether ranges
+	// work, where some servers answer HEAD requests poorly.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var size int64
+	switch resp.StatusCode {
+	case http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable: // The latter for empty files
+		size, err = contentSize(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rawURL, err)
+		}
+	case http.StatusOK:
+		return nil, fmt.Errorf("%s: the server does not support range requests, so the file has to be downloaded first", rawURL)
+	default:
+		return nil, statusError(rawURL, resp)
+	}
+	// Blocks are fetched long after ctx, which only bounds opening, is done.
+	f := &File{url: rawURL, size: size, blocks: make(map[int64]*list.Element), lru: list.New()}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	return f, nil
+}
+
+// contentSize returns the size of the whole file from a Content-Range
+// header, such as "bytes 0-0/1234" or "bytes */0".
+func contentSize(header string) (int64, error) {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok || total == "*" {
+		return 0, fmt.Errorf("the server did not tell the size of the file (Content-Range %q)", header)
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return size, nil
+}
+
+// Size returns the size of the file in bytes.
+func (f *File) Size() int64 { return f.size }
+
+// ReadAt reads len(p) bytes from off. Blocks missing from the cache are
+// fetched together, in a single request, which fails after fetchTimeout or
+// once the file is closed.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%s: negative offset %d", f.url, off)
+	}
+	if off >= f.size {
+		return 0, io.EOF
+	}
+	end := min(off+int64(len(p)), f.size)
+	first, last := off/blockSize, (end-1)/blockSize
+
+	// 1. Take the blocks that are cached, and fetch the others.
+	blocks := make([][]byte, last-first+1)
+	missing := int64(-1) // First block to fetch
+	f.mu.Lock()
+	for n := first; n <= last; n++ {
+		if e, ok := f.blocks[n]; ok {
+			f.lru.MoveToFront(e)
+			blocks[n-first] = e.Value.(*block).data
+		} else if missing < 0 {
+			missing = n
+		}
+	}
+	f.mu.Unlock()
+	if missing >= 0 {
+		upto := last
+		for blocks[upto-first] != nil {
+			upto--
+		}
+		if err := f.fetch(f.ctx, missing, upto, blocks[missing-first:upto-first+1]); err != nil {
+			return 0, err
+		}
+	}
+
+	// 2. Copy the bytes asked for.
+	n := 0
+	for i, data := range blocks {
+		start := int64(0)
+		if i == 0 {
+			start = off - first*blockSize
+		}
+		n += copy(p[n:], data[start:])
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// fetch downloads the blocks from first to last, into blocks, and caches
+// them.
+func (f *File) fetch(ctx context.Context, first, last int64, blocks [][]byte) error {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	start, end := first*blockSize, min((last+1)*blockSize, f.size)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return statusError(f.url, resp)
+	}
+	data := make([]byte, end-start)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return fmt.Errorf("could not read %s: %w", f.url, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range blocks {
+		n := first + int64(i)
+		blocks[i] = data[int64(i)*blockSize : min(int64(i+1)*blockSize, int64(len(data)))]
+		if e, ok := f.blocks[n]; ok {
+			f.lru.MoveToFront(e) // Fetched meanwhile by another reader
+			continue
+		}
+		f.blocks[n] = f.lru.PushFront(&block{n: n, data: blocks[i]})
+		if f.lru.Len() > maxBlocks {
+			oldest := f.lru.Remove(f.lru.Back()).(*block)
+			delete(f.blocks, oldest.n)
+		}
+	}
+	return nil
+}
+
+// Close cancels the requests in progress and drops the cache.
+func (f *File) Close() error {
+	f.cancel()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocks, f.lru = make(map[int64]*list.Element), list.New()
+	return nil
+}
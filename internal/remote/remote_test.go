@@ -0,0 +1,150 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// serve serves content at /data.bin with range support, counting the
+// requests, and 404 for anything else.
+func serve(t *testing.T, content []byte) (string, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		requests.Add(1)
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/data.bin", &requests
+}
+
+func TestFile_ReadAt(t *testing.T) {
+	content := make([]byte, 3*blockSize+123)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+	url, requests := serve(t, content)
+
+	f, err := Open(context.Background(), url+"?token=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Size() != int64(len(content)) {
+		t.Fatalf("expected size %d, got %d", len(content), f.Size())
+	}
+
+	// Reads within a block, across blocks, and past the end.
+	for _, r := range [][2]int{{0, 10}, {blockSize - 5, blockSize + 5}, {100, 3*blockSize + 50}, {len(content) - 20, len(content) + 30}} {
+		p := make([]byte, r[1]-r[0])
+		n, err := f.ReadAt(p, int64(r[0]))
+		want := content[r[0]:min(r[1], len(content))]
+		if r[1] > len(content) && err != io.EOF {
+			t.Errorf("%v: expected io.EOF past the end, got %v", r, err)
+		} else if r[1] <= len(content) && err != nil {
+			t.Errorf("%v: %v", r, err)
+		}
+		if !bytes.Equal(p[:n], want) {
+			t.Errorf("%v: read %d bytes that differ from the file", r, n)
+		}
+	}
+
+	// Every block is cached by now: reading again makes no request.
+	before := requests.Load()
+	p := make([]byte, len(content))
+	if _, err := f.ReadAt(p, 0); err != nil || !bytes.Equal(p, content) {
+		t.Errorf("whole file: %v", err)
+	}
+	if got := requests.Load(); got != before {
+		t.Errorf("expected cached blocks, got %d more requests", got-before)
+	}
+	if _, err := f.ReadAt(p, int64(len(content))); err != io.EOF {
+		t.Errorf("expected io.EOF at the end, got %v", err)
+	}
+}
+
+func TestFile_CloseCancels(t *testing.T) {
+	// The server answers opening, then stalls on every read until the
+	// request is cancelled.
+	stalled := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "bytes=0-0" {
+			http.ServeContent(w, r, "data.bin", time.Time{}, strings.NewReader("ACGT"))
+			return
+		}
+		close(stalled)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	f, err := Open(context.Background(), srv.URL+"/data.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := f.ReadAt(make([]byte, 4), 0)
+		done <- err
+	}()
+	<-stalled
+	f.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the read to be cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the read was not cancelled by Close")
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	url, _ := serve(t, []byte("ACGT"))
+	if _, err := Open(context.Background(), strings.Replace(url, "data", "nope", 1)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: expected fs.ErrNotExist, got %v", err)
+	}
+	if _, err := Get(context.Background(), Sidecar(url, ".fai")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing index: expected fs.ErrNotExist, got %v", err)
+	}
+
+	// A server that ignores ranges would send whole files on every read.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ACGT"))
+	}))
+	defer srv.Close()
+	if _, err := Open(context.Background(), srv.URL+"/genome.fa"); err == nil || !strings.Contains(err.Error(), "range requests") {
+		t.Errorf("expected an error about range requests, got %v", err)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	for _, c := range []struct{ url, base, fai string }{
+		{"https://host/refs/genome.fa", "genome.fa", "https://host/refs/genome.fa.fai"},
+		{"http://host/genome.fa?token=a%2Fb", "genome.fa", "http://host/genome.fa.fai?token=a%2Fb"},
+	} {
+		if !IsURL(c.url) {
+			t.Errorf("%s: expected a URL", c.url)
+		}
+		if got := Base(c.url); got != c.base {
+			t.Errorf("%s: expected base %s, got %s", c.url, c.base, got)
+		}
+		if got := Sidecar(c.url, ".fai"); got != c.fai {
+			t.Errorf("%s: expected index %s, got %s", c.url, c.fai, got)
+		}
+	}
+	if IsURL("genome.fa") || IsURL("/data/http://x") {
+		t.Error("expected paths not to be URLs")
+	}
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+
+	"github.com/guillechuma/bio-tui/internal/state"
+)
+
+// maxRecent is the number of recently opened files remembered.
+const maxRecent = 20
+
+// recentFiles is the on-disk form of the recent files list.
+type recentFiles struct {
+	Paths []string `json:"paths"`
+}
+
+// recentPath returns where the recent files list is kept.
+func recentPath() (string, error) {
+	return state.File("recent", "files")
+}
+
+// Recent returns the recently opened files, most recent first. A missing
+// list is empty.
+func Recent() ([]string, error) {
+	path, err := recentPath()
+	if err != nil {
+		return nil, err
+	}
+	var r recentFiles
+	if err := state.Load(path, &r); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return r.Paths, nil
+}
+
+// AddRecent moves a file to the top of the recent files list, dropping the
+// oldest one when it is full.
+func AddRecent(filePath string) error {
+	// A list that cannot be read is started afresh.
+	paths, _ := Recent()
+	filePath = absPath(filePath)
+	paths = slices.DeleteFunc(paths, func(p string) bool { return p == filePath })
+	paths = append([]string{filePath}, paths...)
+	if len(paths) > maxRecent {
+		paths = paths[:maxRecent]
+	}
+
+	path, err := recentPath()
+	if err != nil {
+		return err
+	}
+	return state.Save(path, recentFiles{Paths: paths})
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/remote"
+	"github.com/guillechuma/bio-tui/internal/state"
+)
+
+// Version is the version of the session format written by this build.
+// Version 1 sessions, which held a single file, are still read.
+const Version = 2
+
+// Session is everything needed to reopen a set of files and show them as
+// they were left.
+type Session struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	Tabs    []Tab     `json:"tabs"`
+	Active  int       `json:"active"` // Index of the tab being shown
+	Sync    bool      `json:"sync"`   // Go-to-region applies to every tab
+}
+
+// Tab is an open file and the state of its viewer.
+type Tab struct {
+	File File `json:"file"`
+	View View `json:"view"`
+}
+
+// Find returns the tab of a file, or nil if the session does not have it.
+// A file that was moved or renamed since is found by its checksum.
+func (s *Session) Find(path string) *Tab {
+	path = absPath(path)
+	for i := range s.Tabs {
+		if s.Tabs[i].File.Path == path {
+			return &s.Tabs[i]
+		}
+	}
+	if !slices.ContainsFunc(s.Tabs, func(t Tab) bool { return t.File.Checksum != "" }) {
+		return nil
+	}
+	checksum := fileChecksum(path)
+	for i := range s.Tabs {
+		if checksum != "" && s.Tabs[i].File.Checksum == checksum {
+			return &s.Tabs[i]
+		}
+	}
+	return nil
+}
+
+// File is the serialized form of the adapter.OpenSpec used to open the file.
+type File struct {
+	Path     string            `json:"path"`
+	Index    string            `json:"index,omitempty"`
+	Aux      map[string]string `json:"aux,omitempty"`
+	Checksum string            `json:"checksum,omitempty"` // See state.Checksum; empty for URLs
+}
+
+// View is the state of the viewer. Positions are 0-based offsets into the
+// reference sequence.
+type View struct {
+	Ref            string `json:"ref"`
+	Cursor         int64  `json:"cursor"`
+	Top            int64  `json:"top"`              // First base of the top row
+	Zoom           int64  `json:"zoom"`             // Bases per column
+	Anchor         *int64 `json:"anchor,omitempty"` // Start of the selection, if any
+	Filter         string `json:"filter,omitempty"` // Search query of the sequence list
+	Focus          string `json:"focus"`
+	ShowGaps       bool   `json:"show_gaps"`
+	ShowBookmarks  bool   `json:"show_bookmarks"`
+	SoftMaskLane   bool   `json:"soft_mask_lane"`
+	Uppercase      bool   `json:"uppercase"`
+	Palette        string `json:"palette"`
+	ProteinPalette string `json:"protein_palette"`
+
+	// Pane layout. The zero values are the defaults, as in sessions saved
+	// before the layout was kept.
+	HideList    bool   `json:"hide_list,omitempty"`
+	HideStats   bool   `json:"hide_stats,omitempty"`
+	ListWidth   int    `json:"list_width,omitempty"`   // Width set by the user, or 0 to follow the terminal
+	StatsHeight int    `json:"stats_height,omitempty"` // Height of the stats below the viewer, or 0 for the configured one
+	Stacked     string `json:"stacked,omitempty"`      // "on" or "off" to override the breakpoint
+}
+
+// FromSpec converts an open spec to its serialized form. Paths are made
+// absolute, so the session can be restored from any directory, and local
+// files are checksummed, so it can find them once moved.
+func FromSpec(spec adapter.OpenSpec) File {
+	path := absPath(spec.Path)
+	return File{Path: path, Index: absPath(spec.Index), Aux: spec.Aux, Checksum: fileChecksum(path)}
+}
+
+// fileChecksum returns the checksum of a local file, or "" for a URL or a
+// file that cannot be read.
+func fileChecksum(path string) string {
+	if path == "" || remote.IsURL(path) {
+		return ""
+	}
+	checksum, err := state.Checksum(path)
+	if err != nil {
+		return ""
+	}
+	return checksum
+}
+
+// absPath returns the absolute form of a path, or the path itself if it is
+// empty, a URL or cannot be resolved.
+func absPath(path string) string {
+	if path == "" || remote.IsURL(path) {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// Spec returns the open spec of the file.
+func (f File) Spec() adapter.OpenSpec {
+	return adapter.OpenSpec{Path: f.Path, Index: f.Index, Aux: f.Aux}
+}
+
+// Load reads a session file, upgrading older versions.
+func Load(path string) (*Session, error) {
+	var raw struct {
+		Session
+		File *File `json:"file"` // Version 1
+		View *View `json:"view"` // Version 1
+	}
+	if err := state.Load(path, &raw); err != nil {
+		return nil, err
+	}
+	s := &raw.Session
+	switch {
+	case s.Version == 1 && raw.File != nil && raw.View != nil:
+		s.Tabs = []Tab{{File: *raw.File, View: *raw.View}}
+		s.Version = Version
+	case s.Version != Version:
+		return nil, fmt.Errorf("session %s has version %d, expected %d", path, s.Version, Version)
+	}
+	return s, nil
+}
+
+// Save writes a session file.
+func Save(path string, s *Session) error {
+	s.Version = Version
+	s.SavedAt = time.Now()
+	return state.Save(path, s)
+}
+
+// LastPath returns where the last session of a file is kept, so that it can
+// be restored the next time the file is opened.
+func LastPath(filePath string) (string, error) {
+	id, err := state.Identify(filePath)
+	if err != nil {
+		return "", err
+	}
+	return state.File("sessions", id.Key)
+}
+
+// LoadLast reads the last session of a file. It returns nil without an error
+// when the file has no saved session.
+func LoadLast(filePath string) (*Session, error) {
+	path, err := LastPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return s, err
+}
@@ -0,0 +1,111 @@
+package session
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+func TestLoad(t *testing.T) {
+	anchor := int64(5)
+	tests := []struct {
+		name    string
+		content string // Missing file when empty
+		want    *Session
+		wantErr error // Or any error when the session is nil
+	}{
+		{
+			name: "current",
+			content: `{"version": 2, "active": 1, "sync": true, "tabs": [
+				{"file": {"path": "/data/a.fa"}, "view": {"ref": "chr1", "cursor": 5, "hide_list": true, "list_width": 30, "stacked": "off"}},
+				{"file": {"path": "https://example.org/b.fa"}, "view": {"ref": "chr2", "anchor": 5}}]}`,
+			want: &Session{Version: Version, Active: 1, Sync: true, Tabs: []Tab{
+				{File: File{Path: "/data/a.fa"}, View: View{Ref: "chr1", Cursor: 5, HideList: true, ListWidth: 30, Stacked: "off"}},
+				{File: File{Path: "https://example.org/b.fa"}, View: View{Ref: "chr2", Anchor: &anchor}},
+			}},
+		},
+		{
+			name:    "version 1",
+			content: `{"version": 1, "file": {"path": "/data/a.fa", "index": "/data/a.fa.fai"}, "view": {"ref": "chr1", "cursor": 5, "zoom": 4}}`,
+			want: &Session{Version: Version, Tabs: []Tab{
+				{File: File{Path: "/data/a.fa", Index: "/data/a.fa.fai"}, View: View{Ref: "chr1", Cursor: 5, Zoom: 4}},
+			}},
+		},
+		{name: "version 1 without a file", content: `{"version": 1, "view": {"ref": "chr1"}}`},
+		{name: "future version", content: `{"version": 3, "tabs": []}`},
+		{name: "not JSON", content: `version: 2`},
+		{name: "missing", wantErr: fs.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := Load(path)
+			if tt.want == nil {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Fatalf("Load() = %+v, %v; expected an error", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() returned an unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, expected %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for name, content := range map[string]string{"a.fa": ">chr1\nACGT\n", "b.fa": ">chr2\nGGCC\n"} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Session{Tabs: []Tab{
+		{File: FromSpec(adapter.OpenSpec{Path: "a.fa"}), View: View{Ref: "chr1"}},
+		{File: FromSpec(adapter.OpenSpec{Path: "b.fa"}), View: View{Ref: "chr2"}},
+		{File: FromSpec(adapter.OpenSpec{Path: "https://example.org/c.fa"}), View: View{Ref: "chr3"}},
+	}}
+	// b.fa is moved after the session is saved.
+	if err := os.Rename("b.fa", "moved.fa"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("other.fa", []byte(">chr4\nTTTT\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string // Ref of the tab found, or "" for none
+	}{
+		{"a.fa", "chr1"},                     // Relative path
+		{filepath.Join(dir, "a.fa"), "chr1"}, // Absolute path
+		{"moved.fa", "chr2"},                 // Checksum
+		{"https://example.org/c.fa", "chr3"}, // URL
+		{"other.fa", ""},                     // Another file
+		{"https://example.org/d.fa", ""},     // Another URL
+		{filepath.Join(dir, "gone.fa"), ""},  // Missing file
+	}
+	for _, tt := range tests {
+		got := s.Find(tt.path)
+		switch {
+		case got == nil && tt.want != "":
+			t.Errorf("Find(%q) found no tab, expected %s", tt.path, tt.want)
+		case got != nil && got.View.Ref != tt.want:
+			t.Errorf("Find(%q) found %s, expected %q", tt.path, got.View.Ref, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/guillechuma/bio-tui/internal/remote"
+)
+
+// fingerprintSpan is the number of bytes hashed at each end of a file.
+const fingerprintSpan = 1 << 20
+
+// Dir returns the directory where bio-tui keeps its state:
+// $XDG_STATE_HOME/bio-tui, ~/.local/state/bio-tui on Unix, or a "state"
+// directory next to the configuration elsewhere.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "bio-tui"), nil
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not locate state directory: %w", err)
+		}
+		return filepath.Join(home, ".local", "state", "bio-tui"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate state directory: %w", err)
+	}
+	return filepath.Join(dir, "bio-tui", "state"), nil
+}
+
+// CacheDir returns the directory where bio-tui keeps files it can rebuild,
+// such as indexes: $XDG_CACHE_HOME/bio-tui, ~/.cache/bio-tui on Unix, or
+// the cache directory of the platform elsewhere.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "bio-tui"), nil
+}
+
+// CacheKey returns the key of a cached file derived from another file: a
+// hash of its absolute path, size and modification time, so the cached
+// file goes stale as soon as the file changes. Unlike Checksum, it reads
+// nothing from the file.
+func CacheKey(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%d\x00%d", abs, info.Size(), info.ModTime().UnixNano()))
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// Checksum returns a quick checksum of a file: the SHA-256 of its size and
+// of its first and last MiB. Reading whole genomes on every start would be
+// too slow, and edits almost always change the size or one of the ends.
+func Checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+
+	h := sha256.New()
+	io.WriteString(h, strconv.FormatInt(size, 10))
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, min(size, fingerprintSpan))); err != nil {
+		return "", err
+	}
+	if size > fingerprintSpan {
+		tail := max(fingerprintSpan, size-fingerprintSpan)
+		if _, err := io.Copy(h, io.NewSectionReader(f, tail, size-tail)); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Identity identifies a file whose state is stored.
+type Identity struct {
+	Path     string // Absolute path of the file, or its URL
+	Checksum string // See Checksum; empty for a URL
+	Key      string // Key under which the state of the file is stored
+}
+
+// Identify computes the identity of a file. The key combines the absolute
+// path with the checksum, so a file that changes starts afresh. A file on a
+// web server is identified by its URL alone, as a checksum would download
+// it.
+func Identify(path string) (Identity, error) {
+	if remote.IsURL(path) {
+		sum := sha256.Sum256([]byte(path))
+		return Identity{Path: path, Key: hex.EncodeToString(sum[:])[:16]}, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Identity{}, err
+	}
+	checksum, err := Checksum(abs)
+	if err != nil {
+		return Identity{}, fmt.Errorf("could not checksum %s: %w", path, err)
+	}
+	sum := sha256.Sum256([]byte(abs + "\x00" + checksum))
+	return Identity{Path: abs, Checksum: checksum, Key: hex.EncodeToString(sum[:])[:16]}, nil
+}
+
+// File returns the path of a state file of the given kind, e.g.
+// "bookmarks", for a key.
+func File(kind, key string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, kind, key+".json"), nil
+}
+
+// Load decodes a JSON state file into v. A missing file is reported with an
+// error that matches os.ErrNotExist.
+func Load(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("could not read state file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Save encodes v as JSON into a state file. The file is replaced atomically,
+// so an interrupted save never leaves it truncated.
+func Save(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("could not create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write state file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
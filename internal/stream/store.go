@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"fmt"
+	"os"
+)
+
+// store keeps the bases of records one after another: in memory up to a
+// limit, then in a temporary file, so that a stream larger than memory can
+// still be browsed.
+type store struct {
+	mem   []byte
+	file  *os.File // Temporary file, once the bases have spilled to disk
+	size  int64
+	limit int64
+}
+
+// append adds bases at the end of the store and returns their offset.
+func (s *store) append(p []byte) (int64, error) {
+	offset := s.size
+	if s.file == nil && s.size+int64(len(p)) > s.limit {
+		if err := s.spill(); err != nil {
+			return 0, err
+		}
+	}
+	if s.file != nil {
+		if _, err := s.file.WriteAt(p, offset); err != nil {
+			return 0, fmt.Errorf("could not write to the temporary file: %w", err)
+		}
+	} else {
+		s.mem = append(s.mem, p...)
+	}
+	s.size += int64(len(p))
+	return offset, nil
+}
+
+// spill moves the bases kept in memory to a temporary file, where every
+// later one goes too.
+func (s *store) spill() error {
+	f, err := os.CreateTemp("", "bio-tui-stream-*")
+	if err != nil {
+		return fmt.Errorf("could not create a temporary file: %w", err)
+	}
+	if _, err := f.Write(s.mem); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("could not write to the temporary file: %w", err)
+	}
+	s.file, s.mem = f, nil
+	return nil
+}
+
+// spilled reports whether the bases are in a temporary file.
+func (s *store) spilled() bool { return s.file != nil }
+
+// read returns a copy of n bases from offset.
+func (s *store) read(offset, n int64) ([]byte, error) {
+	buf := make([]byte, n)
+	if s.file == nil {
+		copy(buf, s.mem[offset:offset+n])
+		return buf, nil
+	}
+	if _, err := s.file.ReadAt(buf, offset); err != nil {
+		return nil, fmt.Errorf("could not read the temporary file: %w", err)
+	}
+	return buf, nil
+}
+
+// close removes the temporary file, if there is one.
+func (s *store) close() error {
+	s.mem = nil
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	if rerr := os.Remove(s.file.Name()); err == nil {
+		err = rerr
+	}
+	s.file = nil
+	return err
+}
@@ -0,0 +1,307 @@
+// Package stream reads FASTA and FASTQ records from stdin or a named pipe,
+// which cannot be indexed, so they can be browsed while they arrive.
+package stream
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/fastq"
+)
+
+// spillSize is how many bases are kept in memory before they spill to a
+// temporary file.
+const spillSize = 256 << 20
+
+// IsStream reports whether a path is read as a stream rather than opened
+// with an index: "-" for stdin, or a named pipe, like the ones of process
+// substitution.
+func IsStream(path string) bool {
+	if path == "-" {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&(os.ModeNamedPipe|os.ModeSocket) != 0
+}
+
+// Name returns how a stream is called in messages and tabs.
+func Name(path string) string {
+	if path == "-" {
+		return "stdin"
+	}
+	return path
+}
+
+// record locates the bases of a record in the store.
+type record struct {
+	name   string
+	offset int64
+	length int64
+}
+
+// Adapter satisfies adapter.Reader and adapter.Stream for records read from
+// a stream. Records are parsed in the background, and each is listed once
+// it is complete: the index is built on the fly.
+type Adapter struct {
+	mu      sync.RWMutex
+	input   io.Closer
+	store   store
+	records []record
+	byName  map[string]int // Index of the first record of each name
+	err     error
+	closed  bool
+	changes chan struct{}
+	notify  adapter.Notify
+	name    string
+}
+
+// Open starts reading the stream of spec: stdin for "-", or else the file,
+// usually a named pipe. It returns at once, before any record is read.
+func (a *Adapter) Open(spec adapter.OpenSpec) error {
+	var input io.ReadCloser = os.Stdin
+	if spec.Path != "-" {
+		f, err := os.Open(spec.Path)
+		if err != nil {
+			return err
+		}
+		input = f
+	}
+	a.notify = spec.Notify
+	a.name = Name(spec.Path)
+	a.start(input, spillSize)
+	return nil
+}
+
+// start reads the records of input in the background, keeping up to limit
+// bytes of bases in memory.
+func (a *Adapter) start(input io.ReadCloser, limit int64) {
+	a.input = input
+	a.store = store{limit: limit}
+	a.byName = make(map[string]int)
+	a.changes = make(chan struct{}, 1)
+	go a.read(input)
+}
+
+// read parses the records of the input, in the format its first character
+// tells, until its end or an error.
+func (a *Adapter) read(input io.Reader) {
+	defer close(a.changes)
+	r := bufio.NewReaderSize(input, 1<<16)
+
+	// 1. Tell the format from the first character that is not a space.
+	var first byte
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			return // No records at all
+		}
+		if err != nil {
+			a.fail(err)
+			return
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			first = c
+			r.UnreadByte()
+			break
+		}
+	}
+	var next func() (string, []byte, error)
+	switch first {
+	case '>':
+		p := fasta.NewParser(r)
+		next = func() (string, []byte, error) {
+			rec, err := p.Next()
+			if err != nil {
+				return "", nil, err
+			}
+			return rec.ID, rec.Seq, nil
+		}
+	case '@':
+		p := fastq.NewParser(r)
+		next = func() (string, []byte, error) {
+			rec, err := p.Next()
+			if err != nil {
+				return "", nil, err
+			}
+			return rec.ID, rec.Seq, nil
+		}
+	default:
+		a.fail(fmt.Errorf("not FASTA nor FASTQ: the data starts with %q", first))
+		return
+	}
+
+	// 2. Add the records as they are parsed.
+	for {
+		id, seq, err := next()
+		if err == io.EOF {
+			return
+		}
+		if err == nil {
+			err = a.add(id, seq)
+		}
+		if err != nil {
+			a.fail(err)
+			return
+		}
+	}
+}
+
+// add stores the bases of a complete record and lists it. Like samtools,
+// the name of a record stops at the first whitespace.
+func (a *Adapter) add(id string, seq []byte) error {
+	name := id
+	if fields := strings.Fields(id); len(fields) > 0 {
+		name = fields[0]
+	}
+
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	spilled := a.store.spilled() // To tell when this record spills
+	offset, err := a.store.append(seq)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	if _, ok := a.byName[name]; !ok {
+		a.byName[name] = len(a.records)
+	}
+	a.records = append(a.records, record{name: name, offset: offset, length: int64(len(seq))})
+	spilled = !spilled && a.store.spilled()
+	a.mu.Unlock()
+
+	if spilled {
+		a.notify.Send(adapter.LevelInfo, "Keeping the sequences of %s in a temporary file past %d MiB", a.name, a.store.limit>>20)
+	}
+	select {
+	case a.changes <- struct{}{}:
+	default: // A change is already waiting to be seen
+	}
+	return nil
+}
+
+// fail records why reading stopped, unless the adapter was closed.
+func (a *Adapter) fail(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.closed {
+		a.err = err
+	}
+}
+
+// Changes returns a channel that receives a value after records are added,
+// and is closed once the input has ended.
+func (a *Adapter) Changes() <-chan struct{} { return a.changes }
+
+// Err returns why reading stopped before the end of the input, if it did.
+func (a *Adapter) Err() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.err
+}
+
+// Close stops reading the input and removes the temporary file.
+func (a *Adapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	if a.input != os.Stdin {
+		a.input.Close()
+	}
+	return a.store.close()
+}
+
+// Capabilities reports the same abilities as the FASTA adapter.
+func (a *Adapter) Capabilities() adapter.Capability {
+	return adapter.CapSymbols | adapter.CapRegions | adapter.CapTracks
+}
+
+// ListSymbols returns the records read so far, in stream order.
+func (a *Adapter) ListSymbols() ([]adapter.Symbol, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	symbols := make([]adapter.Symbol, len(a.records))
+	for i, rec := range a.records {
+		symbols[i] = adapter.Symbol{Name: rec.name, Length: rec.length}
+	}
+	return symbols, nil
+}
+
+// lookup returns the record of a name: the first one, when several records
+// share it, as reads sometimes do.
+func (a *Adapter) lookup(name string) (record, error) {
+	i, ok := a.byName[name]
+	if !ok {
+		return record{}, fmt.Errorf("sequence '%s' not found in %s", name, a.name)
+	}
+	return a.records[i], nil
+}
+
+// LookupSymbol returns the full region of a record.
+func (a *Adapter) LookupSymbol(sym string) (adapter.Region, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	rec, err := a.lookup(sym)
+	if err != nil {
+		return adapter.Region{}, err
+	}
+	return adapter.Region{Ref: sym, Start: 1, End: rec.length}, nil
+}
+
+// Region returns the bases of a region with their stats and tracks.
+func (a *Adapter) Region(reg adapter.Region) (adapter.Slice, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	rec, err := a.lookup(reg.Ref)
+	if err != nil {
+		return adapter.Slice{}, err
+	}
+	if reg.Start < 1 || reg.End > rec.length || reg.Start > reg.End {
+		return adapter.Slice{}, fmt.Errorf("invalid slice coordinates: start %d, end %d for sequence of length %d", reg.Start, reg.End, rec.length)
+	}
+	seq, err := a.store.read(rec.offset+reg.Start-1, reg.End-reg.Start+1)
+	if err != nil {
+		return adapter.Slice{}, err
+	}
+	return fasta.SliceOf(reg, seq), nil
+}
+
+// IterRows is not supported, as for FASTA files.
+func (a *Adapter) IterRows(ch chan<- []string, stop <-chan struct{}) error {
+	return errors.New("IterRows is not supported by the stream adapter")
+}
+
+// Track returns the N runs or soft-masked runs of a whole record.
+func (a *Adapter) Track(ref, name string) ([]adapter.Interval, error) {
+	a.mu.RLock()
+	rec, err := a.lookup(ref)
+	var seq []byte
+	if err == nil {
+		seq, err = a.store.read(rec.offset, rec.length)
+	}
+	a.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	gaps, masked := fasta.FindRuns(ref, seq, 0, true)
+	switch name {
+	case adapter.TrackGaps:
+		return gaps, nil
+	case adapter.TrackSoftMask:
+		return masked, nil
+	default:
+		return nil, fmt.Errorf("track '%s' is not supported by the stream adapter", name)
+	}
+}
@@ -0,0 +1,95 @@
+package stream
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// follow starts an adapter on a pipe, keeping limit bytes in memory, and
+// returns the writing end.
+func follow(t *testing.T, limit int64) (*Adapter, *io.PipeWriter) {
+	t.Helper()
+	pr, pw := io.Pipe()
+	a := &Adapter{name: "test"}
+	a.start(pr, limit)
+	t.Cleanup(func() { pw.Close(); a.Close() })
+	return a, pw
+}
+
+// symbols waits for the next change and returns the records listed then.
+func symbols(t *testing.T, a *Adapter) []adapter.Symbol {
+	t.Helper()
+	<-a.Changes()
+	syms, _ := a.ListSymbols()
+	return syms
+}
+
+func TestStream_FastaWhileArriving(t *testing.T) {
+	a, w := follow(t, 8)
+
+	// A record is listed once the next one starts, as it may have more lines.
+	io.WriteString(w, ">chr1 first\nACGTN\nNNac\n>chr2\n")
+	if got := symbols(t, a); !reflect.DeepEqual(got, []adapter.Symbol{{Name: "chr1", Length: 9}}) {
+		t.Fatalf("expected chr1 only, got %v", got)
+	}
+	io.WriteString(w, "GGCC\n")
+	w.Close()
+	for range a.Changes() {
+	}
+	if err := a.Err(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := a.ListSymbols()
+	if want := []adapter.Symbol{{Name: "chr1", Length: 9}, {Name: "chr2", Length: 4}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// The bases past the limit are read back from the temporary file.
+	if !a.store.spilled() {
+		t.Fatal("expected the bases to spill to disk")
+	}
+	slice, err := a.Region(adapter.Region{Ref: "chr1", Start: 4, End: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(slice.Sequence) != "TNNNac" || slice.Stats["N Count"] != "3" {
+		t.Errorf("unexpected slice %q with stats %v", slice.Sequence, slice.Stats)
+	}
+	gaps, err := a.Track("chr1", adapter.TrackGaps)
+	if err != nil || !reflect.DeepEqual(gaps, []adapter.Interval{{Ref: "chr1", Start: 4, End: 7, Name: adapter.TrackGaps}}) {
+		t.Errorf("unexpected gaps %v, %v", gaps, err)
+	}
+
+	temp := a.store.file.Name()
+	a.Close()
+	if _, err := os.Stat(temp); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to be removed, got %v", err)
+	}
+}
+
+func TestStream_Fastq(t *testing.T) {
+	a, w := follow(t, 1<<20)
+	io.WriteString(w, "\n@read1 lane 1\nACGT\n+\nIIII\n@read2\nGG\n+\nII\n")
+	w.Close()
+	for range a.Changes() {
+	}
+	got, _ := a.ListSymbols()
+	if want := []adapter.Symbol{{Name: "read1", Length: 4}, {Name: "read2", Length: 2}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestStream_NotSequences(t *testing.T) {
+	a, w := follow(t, 1<<20)
+	io.WriteString(w, "chr1\t0\t10\n")
+	w.Close()
+	for range a.Changes() {
+	}
+	if a.Err() == nil {
+		t.Error("expected an error for data that is not FASTA nor FASTQ")
+	}
+}
@@ -0,0 +1,138 @@
+package summary
+
+// Counts holds the base composition of a stretch of sequence.
+type Counts struct {
+	Bases  int64 // Total number of bases
+	GC     int64 // G and C bases
+	N      int64 // N bases (assembly gaps)
+	Masked int64 // Lowercase (soft-masked) bases
+}
+
+// Add accumulates the counts of another stretch.
+func (c *Counts) Add(o Counts) {
+	c.Bases += o.Bases
+	c.GC += o.GC
+	c.N += o.N
+	c.Masked += o.Masked
+}
+
+// GCFraction returns the GC fraction of the called (non-N) bases,
+// or 0 when every base is N.
+func (c Counts) GCFraction() float64 {
+	called := c.Bases - c.N
+	if called <= 0 {
+		return 0
+	}
+	return float64(c.GC) / float64(called)
+}
+
+// NFraction returns the fraction of N bases.
+func (c Counts) NFraction() float64 {
+	if c.Bases == 0 {
+		return 0
+	}
+	return float64(c.N) / float64(c.Bases)
+}
+
+// MaskedFraction returns the fraction of soft-masked bases.
+func (c Counts) MaskedFraction() float64 {
+	if c.Bases == 0 {
+		return 0
+	}
+	return float64(c.Masked) / float64(c.Bases)
+}
+
+// Count computes the composition of a sequence.
+func Count(seq []byte) Counts {
+	c := Counts{Bases: int64(len(seq))}
+	for _, base := range seq {
+		switch base {
+		case 'G', 'g', 'C', 'c':
+			c.GC++
+		case 'N', 'n':
+			c.N++
+		}
+		if base >= 'a' && base <= 'z' {
+			c.Masked++
+		}
+	}
+	return c
+}
+
+const (
+	BaseBin = 1000 // Size of the finest cached bin, in bases
+	Factor  = 10   // Each level's bins merge this many bins of the level below
+)
+
+// Pyramid caches Counts for fixed-size bins at several resolutions
+// (1 kb, 10 kb, 100 kb, ...), so that zoomed-out views of long sequences can
+// be summarized without rescanning the sequence.
+type Pyramid struct {
+	seq    []byte
+	sizes  []int64    // Bin size of each level
+	levels [][]Counts // Bins of each level, finest first
+}
+
+// NewPyramid scans the sequence once and builds every level,
+// up to the first level with a single bin.
+func NewPyramid(seq []byte) *Pyramid {
+	p := &Pyramid{seq: seq}
+
+	// The finest level comes straight from the sequence.
+	size := int64(BaseBin)
+	n := int64(len(seq))
+	bins := make([]Counts, 0, (n+size-1)/size)
+	for start := int64(0); start < n; start += size {
+		bins = append(bins, Count(seq[start:min(start+size, n)]))
+	}
+	p.sizes = append(p.sizes, size)
+	p.levels = append(p.levels, bins)
+
+	// Every coarser level merges bins of the one below.
+	for len(bins) > 1 {
+		merged := make([]Counts, (len(bins)+Factor-1)/Factor)
+		for i, c := range bins {
+			merged[i/Factor].Add(c)
+		}
+		size *= Factor
+		bins = merged
+		p.sizes = append(p.sizes, size)
+		p.levels = append(p.levels, bins)
+	}
+	return p
+}
+
+// Summarize returns the composition of the bases in [start, start+size).
+// Whole cached bins inside the range are taken from the coarsest level that
+// has them; only the unaligned head and tail are counted from the sequence.
+func (p *Pyramid) Summarize(start, size int64) Counts {
+	end := min(start+size, int64(len(p.seq)))
+	var c Counts
+	for start < end {
+		lvl := p.levelAt(start, end)
+		if lvl < 0 {
+			// Count up to the next bin boundary.
+			next := min((start/BaseBin+1)*BaseBin, end)
+			c.Add(Count(p.seq[start:next]))
+			start = next
+			continue
+		}
+		binSize := p.sizes[lvl]
+		c.Add(p.levels[lvl][start/binSize])
+		start += binSize
+	}
+	return c
+}
+
+// levelAt returns the coarsest level with a bin that starts at start and
+// ends by end, or -1 if there is none.
+func (p *Pyramid) levelAt(start, end int64) int {
+	n := int64(len(p.seq))
+	for lvl := len(p.sizes) - 1; lvl >= 0; lvl-- {
+		binSize := p.sizes[lvl]
+		if start%binSize == 0 && min(start+binSize, n) <= end {
+			return lvl
+		}
+	}
+	return -1
+}
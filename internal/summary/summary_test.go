@@ -0,0 +1,28 @@
+package summary
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPyramid_MatchesDirectCount(t *testing.T) {
+	// 25,500 bases, so the last bin of every level is partial.
+	seq := bytes.Repeat([]byte("ACGTNNacgt"), 2550)
+	p := NewPyramid(seq)
+
+	cases := []struct{ start, size int64 }{
+		{0, 1000},      // One cached bin
+		{10000, 10000}, // One bin of the second level
+		{20000, 10000}, // Runs past the end of the sequence
+		{3000, 5000},   // Several bins of the finest level
+		{123, 456},     // Inside one bin, counted directly
+		{750, 23456},   // Unaligned head and tail around whole bins
+	}
+	for _, tc := range cases {
+		end := min(tc.start+tc.size, int64(len(seq)))
+		expected := Count(seq[tc.start:end])
+		if actual := p.Summarize(tc.start, tc.size); actual != expected {
+			t.Errorf("Summarize(%d, %d): expected %+v, got %+v", tc.start, tc.size, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// This file defines how sequence bases are styled in the viewer.
+
+package ui
+
+import (
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/fasta"
+)
+
+// palette returns the active palette for the type of the current sequence.
+func (m *Model) palette() *Palette {
+	if m.seqType == fasta.Protein {
+		return &m.aaPalettes[m.aaPalette]
+	}
+	return &m.ntPalettes[m.ntPalette]
+}
+
+// cyclePalette switches to the next palette for the current sequence type
+// and returns its name.
+func (m *Model) cyclePalette() string {
+	if m.seqType == fasta.Protein {
+		m.aaPalette = (m.aaPalette + 1) % len(m.aaPalettes)
+	} else {
+		m.ntPalette = (m.ntPalette + 1) % len(m.ntPalettes)
+	}
+	return m.palette().Name
+}
+
+// renderBases styles a row of bases for display. Each base is painted by the
+// active palette and soft-masked runs are dimmed. When the uppercase toggle is
+// on, masked bases are shown in uppercase so that only the style marks them
+// as repeats.
+func (m *Model) renderBases(row string) string {
+	p := m.palette()
+
+	var b strings.Builder
+	for i := 0; i < len(row); {
+		// Find the end of the run of bases sharing the same paint.
+		idx := p.index[row[i]]
+		j := i + 1
+		for j < len(row) && p.index[row[j]] == idx {
+			j++
+		}
+
+		run := row[i:j]
+		if m.uppercase {
+			run = strings.ToUpper(run)
+		}
+		b.WriteString(p.paints[idx].prefix)
+		b.WriteString(run)
+		b.WriteString(p.paints[idx].suffix)
+		i = j
+	}
+	return b.String()
+}
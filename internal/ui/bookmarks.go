@@ -0,0 +1,323 @@
+// This file defines the bookmark panel, the jump history and their
+// persistence.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bookmark"
+)
+
+// bookmarkItem represents a single bookmark in the bookmark panel.
+type bookmarkItem struct {
+	bookmark bookmark.Bookmark
+}
+
+// Title is the bookmark name.
+func (i bookmarkItem) Title() string { return i.bookmark.Name }
+
+// Description shows the region and the note, if any.
+func (i bookmarkItem) Description() string {
+	if i.bookmark.Note == "" {
+		return i.bookmark.Region()
+	}
+	return i.bookmark.Region() + "  " + i.bookmark.Note
+}
+
+// FilterValue is the string the list will filter against.
+func (i bookmarkItem) FilterValue() string { return i.bookmark.Name + " " + i.bookmark.Note }
+
+// bookmarkField is the bookmark field being edited in the panel.
+type bookmarkField int
+
+const (
+	editNone bookmarkField = iota
+	editName
+	editNote
+	editImport // The path of a BED file to import
+)
+
+// bookmarksImportedMsg reports the outcome of a BED import.
+type bookmarksImportedMsg struct {
+	path      string
+	bookmarks []bookmark.Bookmark
+	err       error
+}
+
+// Bindings of the bookmark editor, which are fixed like those of the list filter.
+var (
+	editAccept = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save"))
+	editCancel = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel"))
+)
+
+func newBookmarkList(styles Styles) list.Model {
+	ls := list.New(nil, styles.ListDelegate(), 0, 0)
+	ls.Title = "Bookmarks"
+	ls.SetShowHelp(false)
+	ls.SetFilteringEnabled(false)
+	ls.KeyMap.Quit.SetEnabled(false)
+	ls.KeyMap.ForceQuit.SetEnabled(false)
+	return ls
+}
+
+func newBookmarkInput(styles Styles) textinput.Model {
+	ti := textinput.New()
+	ti.PromptStyle = styles.Highlight
+	ti.CharLimit = 200
+	return ti
+}
+
+// updateBookmarkList fills the bookmark panel from the store.
+func (m *Model) updateBookmarkList() tea.Cmd {
+	items := make([]list.Item, len(m.bookmarks.Bookmarks))
+	for i, b := range m.bookmarks.Bookmarks {
+		items[i] = bookmarkItem{bookmark: b}
+	}
+	return m.marks.SetItems(items)
+}
+
+// saveBookmarks writes the store and reports a failure in the panel.
+func (m *Model) saveBookmarks() tea.Cmd {
+	if err := m.bookmarks.Save(); err != nil {
+		return notify(adapter.LevelError, "Could not save bookmarks: %v", err)
+	}
+	return nil
+}
+
+// location returns the current position of the cursor.
+func (m Model) location() bookmark.Location {
+	return bookmark.Location{Ref: m.ref, Pos: m.sliceStart + m.cursor}
+}
+
+// recordJump adds a jump from a location to the current one to the history.
+func (m *Model) recordJump(from bookmark.Location) {
+	if from.Ref != "" && from != m.location() {
+		m.bookmarks.History.Visit(from, m.location())
+	}
+}
+
+// refIndex returns the index of a sequence in the list, or -1 if the file
+// has no sequence of that name.
+func (m Model) refIndex(ref string) int {
+	for i, it := range m.list.Items() {
+		if it.(item).symbol.Name == ref {
+			return i
+		}
+	}
+	return -1
+}
+
+// gotoLocation shows the sequence of a location, switching sequences if
+// needed, and centers the view on its position.
+func (m *Model) gotoLocation(loc bookmark.Location) tea.Cmd {
+	var cmd tea.Cmd
+	if loc.Ref != m.ref {
+		index := m.refIndex(loc.Ref)
+		if index < 0 {
+			return m.list.NewStatusMessage(fmt.Sprintf("Sequence %q is not in this file", loc.Ref))
+		}
+		m.list.ResetFilter()
+		m.list.Select(index)
+		cmd = m.updateViewportContent()
+	}
+	m.selecting = false
+	m.setCursor(loc.Pos - m.sliceStart)
+	m.centerOnCursor()
+	return tea.Batch(cmd, m.takeWindowCmd())
+}
+
+// gotoRegion goes to the start of a region and selects it, recording the
+// jump. A region without an end only moves the cursor.
+func (m *Model) gotoRegion(r adapter.Region) tea.Cmd {
+	from := m.location()
+	cmd := m.gotoLocation(bookmark.Location{Ref: r.Ref, Pos: r.Start - 1})
+	if m.ref != r.Ref {
+		return cmd
+	}
+	if r.End > r.Start {
+		m.anchor = min(r.End-1-m.sliceStart, int64(len(m.currentSlice.Sequence))-1)
+		m.selecting = m.anchor != m.cursor
+		m.refresh()
+	}
+	m.recordJump(from)
+	return cmd
+}
+
+// historyBack returns to the location before the last jump.
+func (m *Model) historyBack() tea.Cmd {
+	loc, ok := m.bookmarks.History.Back(m.location())
+	if !ok {
+		return m.list.NewStatusMessage("No earlier location")
+	}
+	return m.gotoLocation(loc)
+}
+
+// historyForward goes to the location the last back came from.
+func (m *Model) historyForward() tea.Cmd {
+	loc, ok := m.bookmarks.History.Forward(m.location())
+	if !ok {
+		return m.list.NewStatusMessage("No later location")
+	}
+	return m.gotoLocation(loc)
+}
+
+// addBookmark bookmarks the selection, or the base under the cursor.
+func (m *Model) addBookmark() tea.Cmd {
+	if len(m.currentSlice.Sequence) == 0 {
+		return nil
+	}
+	lo, hi, ok := m.selection()
+	if !ok {
+		lo, hi = m.cursor, m.cursor
+	}
+	b := bookmark.Bookmark{Ref: m.ref, Start: m.sliceStart + lo + 1, End: m.sliceStart + hi + 1}
+	b.Name = b.Region()
+	m.bookmarks.Add(b)
+	m.clearSelection()
+
+	return tea.Batch(
+		m.updateBookmarkList(),
+		m.saveBookmarks(),
+		m.list.NewStatusMessage("Bookmarked "+b.Region()),
+	)
+}
+
+// jumpToBookmark goes to the selected bookmark and selects its region.
+func (m *Model) jumpToBookmark() tea.Cmd {
+	selected, ok := m.marks.SelectedItem().(bookmarkItem)
+	if !ok {
+		return nil
+	}
+	b := selected.bookmark
+	return m.gotoRegion(adapter.Region{Ref: b.Ref, Start: b.Start, End: b.End})
+}
+
+// deleteBookmark removes the selected bookmark.
+func (m *Model) deleteBookmark() tea.Cmd {
+	index := m.marks.Index()
+	if _, ok := m.marks.SelectedItem().(bookmarkItem); !ok {
+		return nil
+	}
+	m.bookmarks.Delete(index)
+	return tea.Batch(m.updateBookmarkList(), m.saveBookmarks())
+}
+
+// startEdit opens the editor on a field of the selected bookmark.
+func (m *Model) startEdit(field bookmarkField) tea.Cmd {
+	selected, ok := m.marks.SelectedItem().(bookmarkItem)
+	if !ok {
+		return nil
+	}
+	m.editing = field
+	if field == editName {
+		m.input.Prompt = "Name: "
+		m.input.SetValue(selected.bookmark.Name)
+	} else {
+		m.input.Prompt = "Note: "
+		m.input.SetValue(selected.bookmark.Note)
+	}
+	m.input.CursorEnd()
+	m.resize()
+	return m.input.Focus()
+}
+
+// startImport opens the editor on the path of a BED file to import,
+// offering the name the export action gives the bookmarks of this file.
+func (m *Model) startImport() tea.Cmd {
+	m.editing = editImport
+	m.input.Prompt = "Import BED: "
+	m.input.SetValue(exportName(m.name, "bookmarks", ".bed"))
+	m.input.CursorEnd()
+	m.resize()
+	return m.input.Focus()
+}
+
+// updateEdit handles a message while a bookmark field is being edited.
+func (m *Model) updateEdit(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, editAccept) && m.editing == editImport:
+			path := strings.TrimSpace(m.input.Value())
+			m.stopEdit()
+			if path == "" {
+				return nil
+			}
+			return importBookmarks(path)
+		case key.Matches(keyMsg, editAccept):
+			b := &m.bookmarks.Bookmarks[m.marks.Index()]
+			if m.editing == editName {
+				// An empty name falls back to the region.
+				b.Name = m.input.Value()
+				if b.Name == "" {
+					b.Name = b.Region()
+				}
+			} else {
+				b.Note = m.input.Value()
+			}
+			m.stopEdit()
+			return tea.Batch(m.updateBookmarkList(), m.saveBookmarks())
+		case key.Matches(keyMsg, editCancel):
+			m.stopEdit()
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+// stopEdit closes the editor.
+func (m *Model) stopEdit() {
+	m.editing = editNone
+	m.input.Blur()
+	m.resize()
+}
+
+// importBookmarks reads bookmarks from a BED file.
+func importBookmarks(path string) tea.Cmd {
+	return func() tea.Msg {
+		f, err := os.Open(path)
+		if err != nil {
+			return bookmarksImportedMsg{err: fmt.Errorf("could not open BED file: %w", err)}
+		}
+		defer f.Close()
+
+		bookmarks, err := bookmark.ReadBED(f)
+		if err != nil {
+			return bookmarksImportedMsg{err: fmt.Errorf("%s: %w", path, err)}
+		}
+		return bookmarksImportedMsg{path: path, bookmarks: bookmarks}
+	}
+}
+
+// exportBookmarks writes bookmarks to a new BED file named after the file,
+// such as genome.bookmarks.bed, as createExport says.
+func exportBookmarks(bookmarks []bookmark.Bookmark, source string) tea.Cmd {
+	bookmarks = append([]bookmark.Bookmark(nil), bookmarks...)
+	return func() (msg tea.Msg) {
+		f, path, err := createExport(source, "bookmarks", ".bed")
+		if err != nil {
+			return bedExportedMsg{err: fmt.Errorf("could not create BED file: %w", err)}
+		}
+		defer func() {
+			f.Close()
+			// Leave no partial file behind.
+			if msg.(bedExportedMsg).err != nil {
+				os.Remove(f.Name())
+			}
+		}()
+
+		if err := bookmark.WriteBED(f, bookmarks); err != nil {
+			return bedExportedMsg{err: err}
+		}
+		return bedExportedMsg{path: path, count: len(bookmarks)}
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/bookmark"
+	"github.com/guillechuma/bio-tui/internal/config"
+)
+
+func TestBookmarks_ExportImport(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("genome.bookmarks.bed", []byte("mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	marks := []bookmark.Bookmark{{Ref: "chr1", Start: 11, End: 20, Name: "exon"}}
+
+	// The export never overwrites a file, and says where it wrote instead.
+	exported := exportBookmarks(marks, "genome.fa")().(bedExportedMsg)
+	if exported.err != nil {
+		t.Fatal(exported.err)
+	}
+	if filepath.Base(exported.path) != "genome.bookmarks-2.bed" || exported.count != 1 {
+		t.Errorf("expected 1 bookmark in genome.bookmarks-2.bed, got %d in %s", exported.count, exported.path)
+	}
+	if data, _ := os.ReadFile("genome.bookmarks.bed"); string(data) != "mine\n" {
+		t.Errorf("expected the existing file to be kept, got %q", data)
+	}
+
+	// The import reads the file given at its prompt.
+	m := Model{name: "genome.fa", input: newBookmarkInput(NewStyles(config.Default().Theme))}
+	m.startImport()
+	if got := m.input.Value(); got != "genome.bookmarks.bed" {
+		t.Errorf("expected the import to offer genome.bookmarks.bed, got %q", got)
+	}
+	m.input.SetValue(exported.path)
+	cmd := m.updateEdit(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.editing != editNone || cmd == nil {
+		t.Fatal("expected the prompt to close and the import to start")
+	}
+	imported := cmd().(bookmarksImportedMsg)
+	if imported.err != nil || imported.path != exported.path || len(imported.bookmarks) != 1 || imported.bookmarks[0] != marks[0] {
+		t.Errorf("expected %v from %s, got %v from %s (%v)", marks, exported.path, imported.bookmarks, imported.path, imported.err)
+	}
+}
@@ -0,0 +1,101 @@
+// This file defines the ruler, the base cursor and the status bar of the
+// sequence viewer.
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	rulerTick      = 10 // Columns between tick marks
+	rulerLabel     = 50 // Columns between tick labels
+	rulerHeight    = 2  // Label line and tick line
+	statusHeight   = 1  // Status bar below the sequence
+	laneLabelWidth = 6  // Widest lane label, "masked"
+)
+
+// renderRuler renders the two ruler lines drawn above the sequence: ticks
+// every 10 columns and labels every 50, in bases from the start of each row.
+func (m Model) renderRuler() string {
+	if m.lineWidth <= 0 {
+		return strings.Repeat("\n", rulerHeight-1)
+	}
+
+	labels := []byte(strings.Repeat(" ", m.lineWidth))
+	ticks := []byte(strings.Repeat(" ", m.lineWidth))
+	for col := rulerTick; col <= m.lineWidth; col += rulerTick {
+		ticks[col-1] = '\''
+		if col%rulerLabel != 0 {
+			continue
+		}
+		ticks[col-1] = '|'
+
+		// Right-align the label on its tick, e.g. "500" or "50k".
+		label := rulerText(int64(col) * m.binSize())
+		if start := col - len(label); start >= 0 {
+			copy(labels[start:col], label)
+		}
+	}
+
+	margin := strings.Repeat(" ", m.marginWidth)
+	return m.styles.Ruler.Render(margin+string(labels)) + "\n" +
+		m.styles.Ruler.Render(margin+string(ticks))
+}
+
+// rulerText formats a ruler label compactly, e.g. 50, 500, 5k, 50M.
+func rulerText(n int64) string {
+	return strings.NewReplacer(" bp", "", " kb", "k", " Mb", "M", " Gb", "G").Replace(formatBases(n))
+}
+
+// renderStatus renders the status bar: reference, 1-based cursor position,
+// the base (or bin composition) under the cursor and the zoom.
+func (m Model) renderStatus() string {
+	seq := m.currentSlice.Sequence
+	if len(seq) == 0 {
+		return ""
+	}
+	pos := m.sliceStart + m.cursor + 1
+	status := fmt.Sprintf("%s  pos %d/%d", m.ref, pos, max(m.refLength, m.sliceStart+int64(len(seq))))
+
+	if m.zoom == 0 {
+		status += fmt.Sprintf("  base %c", seq[m.cursor])
+	} else {
+		// Summarize the bin under the cursor.
+		binStart := m.cursor - m.cursor%m.binSize()
+		c := m.summarize(binStart, m.binSize())
+		status += fmt.Sprintf("  GC %.1f%%  N %.1f%%", c.GCFraction()*100, c.NFraction()*100)
+	}
+	if lo, hi, ok := m.selection(); ok {
+		status += fmt.Sprintf("  sel %s (%s)", formatBases(hi-lo+1), m.selectionRegion())
+	}
+	status += "  " + m.zoomLabel()
+	return m.styles.Status.Render(status)
+}
+
+// moveCursor moves the cursor by delta bases, clamped to the sequence,
+// and scrolls the view to keep it visible.
+func (m *Model) moveCursor(delta int64) {
+	m.setCursor(m.cursor + delta)
+}
+
+// setCursor places the cursor on a 0-based offset into the current slice.
+// Past the ends of a window of a remote sequence, the window moves there.
+func (m *Model) setCursor(offset int64) {
+	pos := m.sliceStart + offset
+	m.moveWindow(pos)
+	offset = pos - m.sliceStart
+	last := int64(len(m.currentSlice.Sequence)) - 1
+	m.cursor = max(0, min(offset, last))
+
+	if span := m.rowSpan(); span > 0 {
+		row := m.cursor / span
+		if row < m.topRow {
+			m.topRow = row
+		} else if row >= m.topRow+int64(m.visibleRows) {
+			m.topRow = row - int64(m.visibleRows) + 1
+		}
+	}
+	m.refresh()
+}
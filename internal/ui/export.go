@@ -0,0 +1,126 @@
+// This file defines the export of the view to a PNG figure or to JSON.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/export"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+)
+
+// viewExportedMsg reports the outcome of an export of the view.
+type viewExportedMsg struct {
+	path string
+	err  error
+}
+
+// viewRange returns the offsets into the current slice of what the viewer
+// shows, inclusive: the selection, or else the visible rows.
+func (m Model) viewRange() (lo, hi int64) {
+	if lo, hi, ok := m.selection(); ok {
+		return lo, hi
+	}
+	n := int64(len(m.currentSlice.Sequence))
+	lo = min(m.topRow*m.rowSpan(), n-1)
+	hi = min((m.topRow+int64(m.visibleRows))*m.rowSpan(), n) - 1
+	return lo, hi
+}
+
+// viewSlice returns the region the viewer shows, with its stats and the
+// tracks of the visible lanes.
+func (m *Model) viewSlice() (adapter.Region, adapter.Slice, error) {
+	if len(m.currentSlice.Sequence) == 0 {
+		return adapter.Region{}, adapter.Slice{}, fmt.Errorf("no sequence to export")
+	}
+	lo, hi := m.viewRange()
+	reg := adapter.Region{Ref: m.ref, Start: m.sliceStart + lo + 1, End: m.sliceStart + hi + 1}
+	slice := fasta.SliceOf(reg, m.currentSlice.Sequence[lo:hi+1])
+	var tracks []adapter.Track
+	for _, track := range slice.Tracks {
+		if slices.Contains(m.visibleTracks(), track.Name) {
+			tracks = append(tracks, track)
+		}
+	}
+	slice.Tracks = tracks
+	return reg, slice, nil
+}
+
+// figure returns the figure of what the viewer shows, as it shows it: the
+// same palette, row width and lanes.
+func (m *Model) figure() (export.Figure, error) {
+	reg, slice, err := m.viewSlice()
+	if err != nil {
+		return export.Figure{}, err
+	}
+	if n := int64(len(slice.Sequence)); n > export.MaxFigureBases {
+		return export.Figure{}, fmt.Errorf("the view spans %s, more than the %s a figure can show: zoom in or select a region",
+			formatBases(n), formatBases(export.MaxFigureBases))
+	}
+	return export.Figure{
+		Title:     m.name,
+		Region:    reg,
+		Slice:     slice,
+		Columns:   m.lineWidth,
+		Colors:    m.palette().colors,
+		Theme:     m.theme,
+		Uppercase: m.uppercase,
+	}, nil
+}
+
+// exportFigure writes what the viewer shows to a new PNG file named after
+// the file, such as genome.view.png, as createExport says.
+func (m *Model) exportFigure() tea.Cmd {
+	f, err := m.figure()
+	if err != nil {
+		return notify(adapter.LevelWarn, "Could not export the view: %v", err)
+	}
+	source := m.name
+	return func() tea.Msg {
+		out, path, err := createExport(source, "view", ".png")
+		if err != nil {
+			return viewExportedMsg{err: fmt.Errorf("could not create PNG file: %w", err)}
+		}
+		err = export.WritePNG(out, f)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			// Leave no partial file behind.
+			os.Remove(path)
+			return viewExportedMsg{err: err}
+		}
+		return viewExportedMsg{path: path}
+	}
+}
+
+// exportJSON writes what the viewer shows to a new JSON file named after
+// the file, such as genome.view.json: its bases, stats and visible lanes.
+func (m *Model) exportJSON() tea.Cmd {
+	reg, slice, err := m.viewSlice()
+	if err != nil {
+		return notify(adapter.LevelWarn, "Could not export the view: %v", err)
+	}
+	r := export.NewRegion(m.name, reg, slice)
+	source := m.name
+	return func() tea.Msg {
+		out, path, err := createExport(source, "view", ".json")
+		if err != nil {
+			return viewExportedMsg{err: fmt.Errorf("could not create JSON file: %w", err)}
+		}
+		err = export.WriteJSON(out, r)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			// Leave no partial file behind.
+			os.Remove(path)
+			return viewExportedMsg{err: err}
+		}
+		return viewExportedMsg{path: path}
+	}
+}
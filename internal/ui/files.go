@@ -0,0 +1,60 @@
+// This file defines where exports are written: to new files in the working
+// directory, named after the viewed file, and never over an existing file.
+
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxExportNames is how many numbered names CreateNew tries.
+const maxExportNames = 1000
+
+// exportName returns the name of an export of a file, the file name with
+// what is exported in place of its extension, such as "genome.gaps.bed".
+func exportName(source, what, ext string) string {
+	stem := strings.TrimSuffix(source, filepath.Ext(source))
+	if stem == "" || stem == "." {
+		stem = "bio-tui"
+	}
+	return stem + "." + what + ext
+}
+
+// createExport creates a new file in the working directory for an export of
+// a file, named as exportName says, or numbered as CreateNew says. It
+// returns the file and its absolute path.
+func createExport(source, what, ext string) (*os.File, string, error) {
+	return CreateNew(exportName(source, what, ext))
+}
+
+// CreateNew creates a file of a name that is not taken. A file of that name
+// is never overwritten: a number is added before the extension instead, as
+// in "genome.gaps-2.bed". It returns the file and its absolute path.
+func CreateNew(name string) (*os.File, string, error) {
+	first := name
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 1; n <= maxExportNames; n++ {
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		path, err := filepath.Abs(name)
+		if err != nil {
+			path = name
+		}
+		return f, path, nil
+	}
+	return nil, "", fmt.Errorf("%s and %d numbered names like it exist already", first, maxExportNames-1)
+}
@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateExport(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("genome.gaps.bed", []byte("mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// An existing file is kept, and the next free name is used.
+	for _, want := range []string{"genome.gaps-2.bed", "genome.gaps-3.bed"} {
+		f, path, err := createExport("genome.fa", "gaps", ".bed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		if !filepath.IsAbs(path) || filepath.Base(path) != want {
+			t.Errorf("expected the absolute path of %s, got %s", want, path)
+		}
+	}
+	if data, _ := os.ReadFile("genome.gaps.bed"); string(data) != "mine\n" {
+		t.Errorf("expected the existing file to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,168 @@
+// This file defines the N-run (gap) panel and its BED export.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bed"
+)
+
+// runItem represents a single N-run or soft-masked run in the gap panel.
+type runItem struct {
+	interval adapter.Interval
+}
+
+// Title shows the run in 1-based, inclusive coordinates, like a region string.
+func (i runItem) Title() string {
+	return fmt.Sprintf("%s:%d-%d", i.interval.Ref, i.interval.Start+1, i.interval.End)
+}
+
+// Description shows the length and the kind of run.
+func (i runItem) Description() string {
+	return fmt.Sprintf("%d bp %s", i.interval.Len(), adapter.TrackLabel(i.interval.Name))
+}
+
+// FilterValue is the string the list will filter against.
+func (i runItem) FilterValue() string { return i.Title() }
+
+// bedExportedMsg reports the outcome of a BED export.
+type bedExportedMsg struct {
+	path  string
+	count int
+	err   error
+}
+
+// lane draws one track under the wrapped sequence rows. It keeps a cursor into
+// the track's sorted intervals, so each interval is visited only once per render.
+type lane struct {
+	label     string
+	intervals []adapter.Interval
+	next      int
+}
+
+// render marks the bases of [rowStart, rowEnd) covered by the track.
+// It reports false when the row has no coverage, so no lane line is drawn.
+func (l *lane) render(rowStart, rowEnd int64) (string, bool) {
+	for l.next < len(l.intervals) && l.intervals[l.next].End <= rowStart {
+		l.next++
+	}
+	if l.next >= len(l.intervals) || l.intervals[l.next].Start >= rowEnd {
+		return "", false
+	}
+
+	marks := []byte(strings.Repeat(" ", int(rowEnd-rowStart)))
+	for k := l.next; k < len(l.intervals) && l.intervals[k].Start < rowEnd; k++ {
+		from := max(l.intervals[k].Start, rowStart)
+		to := min(l.intervals[k].End, rowEnd)
+		for p := from; p < to; p++ {
+			marks[p-rowStart] = '#'
+		}
+	}
+	return strings.TrimRight(string(marks), " "), true
+}
+
+// newGapList creates the list component backing the gap panel.
+func newGapList(styles Styles) list.Model {
+	ls := list.New(nil, styles.ListDelegate(), 0, 0)
+	ls.Title = "N-runs"
+	ls.SetShowHelp(false)
+	ls.SetFilteringEnabled(false)
+	ls.KeyMap.Quit.SetEnabled(false)
+	ls.KeyMap.ForceQuit.SetEnabled(false)
+	return ls
+}
+
+// visibleTracks returns the names of the tracks currently drawn as lanes.
+func (m *Model) visibleTracks() []string {
+	names := []string{adapter.TrackGaps}
+	if m.showSoftMask {
+		names = append(names, adapter.TrackSoftMask)
+	}
+	return names
+}
+
+// sliceTrack returns the intervals of the named track in the current slice.
+func (m *Model) sliceTrack(name string) []adapter.Interval {
+	for _, track := range m.currentSlice.Tracks {
+		if track.Name == name {
+			return track.Intervals
+		}
+	}
+	return nil
+}
+
+// updateGapList fills the gap panel with the runs of the current slice.
+func (m *Model) updateGapList() tea.Cmd {
+	var items []list.Item
+	for _, name := range m.visibleTracks() {
+		for _, iv := range m.sliceTrack(name) {
+			items = append(items, runItem{interval: iv})
+		}
+	}
+	return m.gaps.SetItems(items)
+}
+
+// jumpToRun puts the cursor on the first base of the selected run and
+// centers the view on it.
+func (m *Model) jumpToRun() {
+	selected, ok := m.gaps.SelectedItem().(runItem)
+	if !ok {
+		return
+	}
+	from := m.location()
+	m.setCursor(selected.interval.Start - m.sliceStart)
+	m.centerOnCursor()
+	m.recordJump(from)
+}
+
+// exportRuns writes the runs of the given tracks for every reference to a new
+// BED file named after the file, such as genome.gaps.bed, as createExport
+// says. It runs as a command so that scanning large genomes does not block
+// the UI.
+func exportRuns(reader adapter.Reader, refs []string, names []string, source string) tea.Cmd {
+	return func() (msg tea.Msg) {
+		if reader.Capabilities()&adapter.CapTracks == 0 {
+			return bedExportedMsg{err: fmt.Errorf("this file type does not provide tracks")}
+		}
+
+		f, path, err := createExport(source, "gaps", ".bed")
+		if err != nil {
+			return bedExportedMsg{err: fmt.Errorf("could not create BED file: %w", err)}
+		}
+		defer func() {
+			f.Close()
+			// Leave no partial file behind.
+			if msg.(bedExportedMsg).err != nil {
+				os.Remove(f.Name())
+			}
+		}()
+
+		w := bed.NewWriter(f)
+		count := 0
+		for _, ref := range refs {
+			for _, name := range names {
+				intervals, err := reader.Track(ref, name)
+				if err != nil {
+					return bedExportedMsg{err: err}
+				}
+				for _, iv := range intervals {
+					rec := bed.Record{Chrom: iv.Ref, Start: iv.Start, End: iv.End, Name: iv.Name}
+					if err := w.Write(rec); err != nil {
+						return bedExportedMsg{err: err}
+					}
+					count++
+				}
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return bedExportedMsg{err: err}
+		}
+		return bedExportedMsg{path: path, count: count}
+	}
+}
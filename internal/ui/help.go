@@ -0,0 +1,150 @@
+// This file defines the footer help line and the full help overlay.
+
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// helpGroup is a titled column of bindings in the help overlay.
+type helpGroup struct {
+	title    string
+	bindings []key.Binding
+}
+
+// newHelp creates the help component used for the footer and the overlay.
+func newHelp(styles Styles) help.Model {
+	h := help.New()
+	h.Styles.ShortKey = h.Styles.ShortKey.Foreground(styles.Highlight.GetForeground())
+	h.Styles.FullKey = h.Styles.FullKey.Foreground(styles.Highlight.GetForeground())
+	return h
+}
+
+// applyCapabilities disables the bindings of features the open adapter does
+// not provide, which also hides them from the help.
+func (k *KeyMap) applyCapabilities(caps adapter.Capability) {
+	hasTracks := caps&adapter.CapTracks != 0
+	k.ToggleGaps.SetEnabled(hasTracks)
+	k.ToggleSoftMask.SetEnabled(hasTracks)
+	k.ExportBED.SetEnabled(hasTracks)
+	k.ExportPNG.SetEnabled(caps&adapter.CapRegions != 0)
+	k.ExportJSON.SetEnabled(caps&adapter.CapRegions != 0)
+}
+
+// shortHelp returns the bindings shown in the footer for the focused pane.
+func (m Model) shortHelp() []key.Binding {
+	var bindings []key.Binding
+	switch m.focus {
+	case focusList:
+		bindings = []key.Binding{m.list.KeyMap.CursorUp, m.list.KeyMap.CursorDown, m.list.KeyMap.Filter}
+	case focusViewport:
+		if m.selecting {
+			bindings = []key.Binding{m.keys.Copy, m.keys.CopyFASTA, m.keys.CopyRevComp, m.keys.ExportSelection, m.keys.Visual}
+			break
+		}
+		bindings = []key.Binding{m.keys.CursorLeft, m.keys.CursorRight, m.keys.CursorUp, m.keys.CursorDown, m.keys.ZoomIn, m.keys.ZoomOut, m.keys.Visual}
+	case focusGaps:
+		bindings = []key.Binding{m.gaps.KeyMap.CursorUp, m.gaps.KeyMap.CursorDown, m.keys.Jump, m.keys.ExportBED}
+	case focusBookmarks:
+		if m.editing != editNone {
+			return []key.Binding{editAccept, editCancel}
+		}
+		bindings = []key.Binding{m.keys.Jump, m.keys.RenameBookmark, m.keys.EditNote, m.keys.DeleteBookmark, m.keys.ImportBookmarks, m.keys.ExportBookmarks}
+	}
+	return append(bindings, m.keys.FocusNext, m.keys.Help, m.keys.Quit)
+}
+
+// helpGroups returns every binding, grouped by the pane it applies to.
+func (m Model) helpGroups() []helpGroup {
+	lk := m.list.KeyMap
+	vk := m.viewport.KeyMap
+	groups := []helpGroup{
+		{"List", []key.Binding{lk.CursorUp, lk.CursorDown, lk.PrevPage, lk.NextPage, lk.GoToStart, lk.GoToEnd}},
+		{"Search", enabled(lk.Filter, lk.AcceptWhileFiltering, lk.CancelWhileFiltering, lk.ClearFilter)},
+		{"Viewport", []key.Binding{
+			m.keys.CursorLeft, m.keys.CursorRight, m.keys.CursorUp, m.keys.CursorDown,
+			vk.PageUp, vk.PageDown, vk.HalfPageUp, vk.HalfPageDown, m.keys.ZoomIn, m.keys.ZoomOut,
+		}},
+		{"Selection", []key.Binding{m.keys.Visual, m.keys.Copy, m.keys.CopyFASTA, m.keys.CopyRevComp, m.keys.ExportSelection}},
+		{"N-runs", []key.Binding{m.keys.ExportBED}},
+		{"Export", []key.Binding{m.keys.ExportPNG, m.keys.ExportJSON}},
+		{"Bookmarks", []key.Binding{
+			m.keys.AddBookmark, m.keys.Jump, m.keys.RenameBookmark, m.keys.EditNote, m.keys.DeleteBookmark,
+			m.keys.ImportBookmarks, m.keys.ExportBookmarks, m.keys.HistoryBack, m.keys.HistoryForward,
+		}},
+		{"Tabs", []key.Binding{m.keys.NextTab, m.keys.PrevTab, m.keys.GotoRegion, m.keys.ToggleSync, m.keys.OpenFile}},
+		{"Layout", []key.Binding{
+			m.keys.ToggleList, m.keys.ToggleStats, m.keys.ToggleStacked,
+			m.keys.GrowList, m.keys.ShrinkList, m.keys.GrowStats, m.keys.ShrinkStats,
+		}},
+		{"Commands", []key.Binding{
+			m.keys.FocusNext, m.keys.ToggleGaps, m.keys.ToggleBookmarks, m.keys.ToggleSoftMask, m.keys.ToggleUpper,
+			m.keys.CyclePalette, m.keys.MessageLog, m.keys.Help, m.keys.Quit,
+		}},
+	}
+
+	// Drop groups whose bindings are all disabled for the open adapter.
+	visible := groups[:0]
+	for _, g := range groups {
+		for _, b := range g.bindings {
+			if b.Enabled() {
+				visible = append(visible, g)
+				break
+			}
+		}
+	}
+	return visible
+}
+
+// enabled returns copies of the bindings that are always shown. The list
+// enables its search bindings only while a filter is typed or applied.
+func enabled(bindings ...key.Binding) []key.Binding {
+	for i := range bindings {
+		bindings[i].SetEnabled(true)
+	}
+	return bindings
+}
+
+// renderFooter renders the one-line help for the focused pane, cut to the
+// terminal width.
+func (m Model) renderFooter() string {
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(m.help.ShortHelpView(m.shortHelp()))
+}
+
+// renderHelpOverlay renders the full help, one titled column per pane,
+// centered in the terminal.
+func (m Model) renderHelpOverlay() string {
+	title := m.styles.Highlight.Bold(true)
+
+	columns := make([]string, 0, len(m.helpGroups()))
+	for _, g := range m.helpGroups() {
+		column := lipgloss.JoinVertical(lipgloss.Left,
+			title.Render(g.title),
+			m.help.FullHelpView([][]key.Binding{g.bindings}),
+		)
+		columns = append(columns, lipgloss.NewStyle().PaddingRight(4).PaddingBottom(1).Render(column))
+	}
+
+	// Flow the columns into as many rows as needed to fit the terminal width.
+	maxWidth := m.width - m.styles.Active.GetHorizontalFrameSize()
+	var rows []string
+	var row []string
+	rowWidth := 0
+	for _, column := range columns {
+		w := lipgloss.Width(column)
+		if len(row) > 0 && rowWidth+w > maxWidth {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+			row, rowWidth = nil, 0
+		}
+		row = append(row, column)
+		rowWidth += w
+	}
+	rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+
+	body := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	box := m.styles.Active.Render(body)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
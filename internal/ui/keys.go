@@ -0,0 +1,114 @@
+// This file defines the key bindings of the TUI.
+
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the bindings for every action handled by the model itself.
+// Navigation inside the list and viewport is left to the bubbles components.
+type KeyMap struct {
+	Quit            key.Binding
+	FocusNext       key.Binding
+	CursorLeft      key.Binding
+	CursorRight     key.Binding
+	CursorUp        key.Binding
+	CursorDown      key.Binding
+	ZoomIn          key.Binding
+	ZoomOut         key.Binding
+	Visual          key.Binding
+	Copy            key.Binding
+	CopyFASTA       key.Binding
+	CopyRevComp     key.Binding
+	ExportSelection key.Binding
+	AddBookmark     key.Binding
+	ToggleBookmarks key.Binding
+	RenameBookmark  key.Binding
+	EditNote        key.Binding
+	DeleteBookmark  key.Binding
+	ImportBookmarks key.Binding
+	ExportBookmarks key.Binding
+	HistoryBack     key.Binding
+	HistoryForward  key.Binding
+	NextTab         key.Binding
+	PrevTab         key.Binding
+	GotoRegion      key.Binding
+	ToggleSync      key.Binding
+	OpenFile        key.Binding
+	MessageLog      key.Binding
+	ToggleList      key.Binding
+	ToggleStats     key.Binding
+	ToggleStacked   key.Binding
+	GrowList        key.Binding
+	ShrinkList      key.Binding
+	GrowStats       key.Binding
+	ShrinkStats     key.Binding
+	ToggleGaps      key.Binding
+	ToggleSoftMask  key.Binding
+	ToggleUpper     key.Binding
+	CyclePalette    key.Binding
+	Jump            key.Binding
+	ExportBED       key.Binding
+	ExportPNG       key.Binding
+	ExportJSON      key.Binding
+	Help            key.Binding
+}
+
+// NewKeyMap builds the key map from the configured action bindings,
+// e.g. "quit" -> ["q", "ctrl+c"].
+func NewKeyMap(keys map[string][]string) KeyMap {
+	return KeyMap{
+		Quit:            newBinding(keys["quit"], "quit"),
+		FocusNext:       newBinding(keys["focus_next"], "switch pane"),
+		CursorLeft:      newBinding(keys["cursor_left"], "previous base"),
+		CursorRight:     newBinding(keys["cursor_right"], "next base"),
+		CursorUp:        newBinding(keys["cursor_up"], "row up"),
+		CursorDown:      newBinding(keys["cursor_down"], "row down"),
+		ZoomIn:          newBinding(keys["zoom_in"], "zoom in"),
+		ZoomOut:         newBinding(keys["zoom_out"], "zoom out"),
+		Visual:          newBinding(keys["visual"], "select"),
+		Copy:            newBinding(keys["copy"], "copy"),
+		CopyFASTA:       newBinding(keys["copy_fasta"], "copy FASTA"),
+		CopyRevComp:     newBinding(keys["copy_revcomp"], "copy rev. comp."),
+		ExportSelection: newBinding(keys["export_selection"], "export selection BED"),
+		AddBookmark:     newBinding(keys["add_bookmark"], "add bookmark"),
+		ToggleBookmarks: newBinding(keys["toggle_bookmarks"], "bookmark panel"),
+		RenameBookmark:  newBinding(keys["rename_bookmark"], "rename"),
+		EditNote:        newBinding(keys["edit_note"], "edit note"),
+		DeleteBookmark:  newBinding(keys["delete_bookmark"], "delete"),
+		ImportBookmarks: newBinding(keys["import_bookmarks"], "import BED"),
+		ExportBookmarks: newBinding(keys["export_bookmarks"], "export BED"),
+		HistoryBack:     newBinding(keys["history_back"], "back"),
+		HistoryForward:  newBinding(keys["history_forward"], "forward"),
+		NextTab:         newBinding(keys["next_tab"], "next tab"),
+		PrevTab:         newBinding(keys["prev_tab"], "previous tab"),
+		GotoRegion:      newBinding(keys["goto_region"], "go to region"),
+		ToggleSync:      newBinding(keys["toggle_sync"], "sync tabs"),
+		OpenFile:        newBinding(keys["open_file"], "open file"),
+		MessageLog:      newBinding(keys["message_log"], "messages"),
+		ToggleList:      newBinding(keys["toggle_list"], "list pane"),
+		ToggleStats:     newBinding(keys["toggle_stats"], "stats pane"),
+		ToggleStacked:   newBinding(keys["toggle_stacked"], "stack panes"),
+		GrowList:        newBinding(keys["grow_list"], "wider list"),
+		ShrinkList:      newBinding(keys["shrink_list"], "narrower list"),
+		GrowStats:       newBinding(keys["grow_stats"], "taller stats"),
+		ShrinkStats:     newBinding(keys["shrink_stats"], "shorter stats"),
+		ToggleGaps:      newBinding(keys["toggle_gaps"], "N-run panel"),
+		ToggleSoftMask:  newBinding(keys["toggle_soft_mask"], "soft-mask lane"),
+		ToggleUpper:     newBinding(keys["toggle_uppercase"], "uppercase"),
+		CyclePalette:    newBinding(keys["cycle_palette"], "palette"),
+		Jump:            newBinding(keys["jump"], "jump"),
+		ExportBED:       newBinding(keys["export_bed"], "export BED"),
+		ExportPNG:       newBinding(keys["export_png"], "export PNG"),
+		ExportJSON:      newBinding(keys["export_json"], "export JSON"),
+		Help:            newBinding(keys["help"], "help"),
+	}
+}
+
+// newBinding creates a binding whose help lists all of its keys.
+func newBinding(keys []string, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), desc))
+}
@@ -0,0 +1,236 @@
+// This file defines how the panes are laid out for the terminal size: the
+// breakpoints, the collapsible panes and the user-resizable splits.
+
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Layout breakpoints and limits, in terminal cells.
+const (
+	narrowWidth      = 100 // Below this width, the list and the viewer are stacked
+	wideWidth        = 180 // From this width on, the stats sit beside the viewer
+	statsSideWidth   = 36  // Width of the stats pane beside the viewer
+	maxAutoListWidth = 60  // Widest the list gets unless resized by the user
+	minListWidth     = 20
+	minMainWidth     = 40 // Narrowest the viewer column gets beside the list
+	minWidth         = 40 // Below this width, nothing is drawn
+	minViewportRows  = 3  // Fewest sequence rows worth drawing
+	minStatsHeight   = 3
+	listStep         = 4 // Columns the list grows or shrinks per key press
+)
+
+// stackMode says whether the list and the viewer share the screen or take
+// turns at it.
+type stackMode int
+
+const (
+	stackAuto stackMode = iota // Stacked on narrow terminals only
+	stackOn                    // Always stacked
+	stackOff                   // Always side by side
+)
+
+// geometry is the size of every pane for the current terminal size.
+type geometry struct {
+	listWidth   int  // Width of the list pane, frame included; 0 when hidden
+	mainWidth   int  // Width of the viewer column, the stats beside it included; 0 when hidden
+	statsWidth  int  // Width of the stats pane beside the viewer; 0 when below or hidden
+	statsHeight int  // Height of the stats pane below the viewer; 0 when beside or hidden
+	tooSmall    bool // The terminal cannot fit the open panes
+	needHeight  int  // Height needed by the open panes
+}
+
+// stacked reports whether only one of the list and the viewer is shown.
+func (m Model) stacked() bool {
+	switch m.stack {
+	case stackOn:
+		return true
+	case stackOff:
+		return false
+	default:
+		return m.width < narrowWidth
+	}
+}
+
+// toggleStacked switches between stacked and side-by-side panes, overriding
+// the breakpoint.
+func (m *Model) toggleStacked() {
+	if m.stacked() {
+		m.stack = stackOff
+	} else {
+		m.stack = stackOn
+	}
+	m.resize()
+}
+
+// toggleList collapses or reopens the sequence list.
+func (m *Model) toggleList() {
+	m.showList = !m.showList
+	if !m.showList && m.focus == focusList {
+		m.focus = focusViewport
+	}
+	m.resize()
+}
+
+// toggleStats collapses or reopens the stats pane.
+func (m *Model) toggleStats() {
+	m.showStats = !m.showStats
+	m.resize()
+}
+
+// resizeList moves the split between the list and the viewer by delta
+// columns. From then on, the list keeps its width when the terminal resizes.
+func (m *Model) resizeList(delta int) {
+	if m.geo.listWidth == 0 || m.stacked() {
+		return
+	}
+	m.listWidth = m.geo.listWidth + delta
+	m.resize()
+}
+
+// resizeStats moves the split between the viewer and the stats below it.
+func (m *Model) resizeStats(delta int) {
+	m.statsHeight = max(minStatsHeight, min(m.statsHeight+delta, m.height/2))
+	m.resize()
+}
+
+// autoListWidth returns the width of the list beside the viewer: the width
+// set by the user, or else the configured share of the terminal, capped on
+// wide terminals. Either way the viewer keeps its minimum width.
+func (m Model) autoListWidth() int {
+	w := m.listWidth
+	if w == 0 {
+		w = min(int(float64(m.width)*m.layout.ListWidth), maxAutoListWidth)
+	}
+	return max(minListWidth, min(w, m.width-minMainWidth))
+}
+
+// computeGeometry lays the panes out for the terminal size.
+func (m Model) computeGeometry() geometry {
+	var g geometry
+
+	// 1. Columns: the list and the viewer, or one of them when stacked.
+	switch {
+	case m.stacked() && m.showList && m.focus == focusList:
+		g.listWidth = m.width
+	case m.stacked() || !m.showList:
+		g.listWidth = 0
+	default:
+		g.listWidth = m.autoListWidth()
+	}
+	g.mainWidth = m.width - g.listWidth
+
+	// 2. The stats sit beside the viewer when there is room, else below it.
+	if m.showStats && g.mainWidth > 0 {
+		if m.width >= wideWidth {
+			g.statsWidth = statsSideWidth
+		} else {
+			g.statsHeight = m.statsHeight
+		}
+	}
+
+	// 3. The viewer column must fit its frame, a few rows and the open
+	// panels. The stats below give way first.
+	g.needHeight = footerHeight + m.styles.Active.GetVerticalFrameSize() + rulerHeight + statusHeight + minViewportRows
+	if m.showGaps {
+		g.needHeight += m.layout.GapsHeight
+	}
+	if m.showBookmarks {
+		g.needHeight += m.layout.BookmarksHeight
+	}
+	if m.height < g.needHeight+g.statsHeight {
+		g.statsHeight = 0
+	}
+	g.tooSmall = m.width < minWidth || m.height < g.needHeight
+	return g
+}
+
+// viewerWidth returns the width of the viewport and the panels below it.
+// While the list fills a stacked screen, it is the width they will get.
+func (m Model) viewerWidth() int {
+	if m.geo.mainWidth == 0 {
+		return m.width - m.geo.statsWidth
+	}
+	return m.geo.mainWidth - m.geo.statsWidth
+}
+
+// listPaneWidth returns the width of the list pane, frame included.
+func (m Model) listPaneWidth() int {
+	return m.geo.listWidth
+}
+
+// renderPanel renders a panel below the viewport across the full width of
+// the viewer column.
+func (m Model) renderPanel(pane focusState, content string) string {
+	style := m.paneStyle(pane)
+	return style.Width(m.viewerWidth() - style.GetHorizontalBorderSize()).Render(content)
+}
+
+// resize lays out the panes for the current terminal size.
+func (m *Model) resize() {
+	m.geo = m.computeGeometry()
+	m.help.Width = m.width
+	if m.geo.tooSmall {
+		return
+	}
+
+	// Get styles and their overhead
+	listStyle := m.paneStyle(focusList)
+	viewportStyle := m.paneStyle(focusViewport)
+
+	// Leave one line for the help footer.
+	paneHeight := m.height - footerHeight
+	viewerWidth := m.viewerWidth()
+
+	// Size list (subtract both H and V frames)
+	if m.geo.listWidth > 0 {
+		m.list.SetSize(
+			m.geo.listWidth-listStyle.GetHorizontalFrameSize(),
+			paneHeight-listStyle.GetVerticalFrameSize(),
+		)
+	}
+
+	// Size viewport (subtract both H and V frames)
+	m.viewport.Width = viewerWidth - viewportStyle.GetHorizontalFrameSize()
+	m.viewport.Height = paneHeight - m.geo.statsHeight - viewportStyle.GetVerticalFrameSize()
+	m.viewport.Height -= rulerHeight + statusHeight
+
+	// Make room for the gap panel below the viewport.
+	if m.showGaps {
+		gapsStyle := m.paneStyle(focusGaps)
+		m.viewport.Height -= m.layout.GapsHeight
+		m.gaps.SetSize(
+			viewerWidth-gapsStyle.GetHorizontalFrameSize(),
+			m.layout.GapsHeight-gapsStyle.GetVerticalFrameSize(),
+		)
+	}
+
+	// And for the bookmark panel, keeping a line for the editor while it is open.
+	if m.showBookmarks {
+		marksStyle := m.paneStyle(focusBookmarks)
+		m.viewport.Height -= m.layout.BookmarksHeight
+		m.input.Width = viewerWidth - marksStyle.GetHorizontalFrameSize() - lipgloss.Width(m.input.Prompt) - 1
+		listHeight := m.layout.BookmarksHeight - marksStyle.GetVerticalFrameSize()
+		if m.editing != editNone {
+			listHeight--
+		}
+		m.marks.SetSize(viewerWidth-marksStyle.GetHorizontalFrameSize(), listHeight)
+	}
+
+	// Re-wrap the sequence with the new viewport size
+	m.refresh()
+}
+
+// renderTooSmall renders the notice shown when the open panes do not fit.
+func (m Model) renderTooSmall() string {
+	needWidth := max(minWidth, m.width)
+	notice := fmt.Sprintf("Terminal too small: %d×%d\nNeeds at least %d×%d", m.width, m.height, needWidth, max(m.geo.needHeight, m.height))
+	if m.showGaps || m.showBookmarks {
+		notice += "\nClose the open panels to make room"
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().Align(lipgloss.Center).Render(notice))
+}
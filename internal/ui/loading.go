@@ -0,0 +1,226 @@
+// This file defines the tabs whose file is still being opened in the
+// background, such as while its index is built, with their progress bar.
+
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// progressWidth is the width of the progress bar of a loading tab.
+const progressWidth = 40
+
+// job opens a file in the background for a tab.
+type job struct {
+	id       int
+	spec     adapter.OpenSpec
+	progress adapter.Progress // Latest progress reported
+	updates  chan adapter.Progress
+	cancel   context.CancelFunc
+	started  time.Time
+}
+
+// progressMsg carries the progress of a job.
+type progressMsg struct {
+	id       int
+	progress adapter.Progress
+}
+
+// fileOpenedMsg reports the outcome of a job.
+type fileOpenedMsg struct {
+	id    int
+	spec  adapter.OpenSpec
+	model Model
+	err   error
+}
+
+// loadCancel cancels the opening of a file, which is fixed like the list
+// filter keys.
+var loadCancel = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel"))
+
+// newJob prepares the opening of a file, which can be cancelled.
+func newJob(id int, spec adapter.OpenSpec) *job {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		id:      id,
+		updates: make(chan adapter.Progress, 1),
+		cancel:  cancel,
+		started: time.Now(),
+	}
+	spec.Context = ctx
+	spec.Progress = func(p adapter.Progress) {
+		select {
+		case j.updates <- p:
+		default: // The UI has not caught up with the last one, so skip this one
+		}
+	}
+	j.spec = spec
+	return j
+}
+
+// run returns a command that opens the file and delivers the outcome.
+func (j *job) run(open OpenFunc) tea.Cmd {
+	return func() tea.Msg {
+		m, err := open(j.spec)
+		close(j.updates)
+		j.cancel()
+		return fileOpenedMsg{id: j.id, spec: j.spec, model: m, err: err}
+	}
+}
+
+// listen returns a command that waits for the next progress of the job.
+func (j *job) listen() tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-j.updates
+		if !ok {
+			return nil
+		}
+		return progressMsg{id: j.id, progress: p}
+	}
+}
+
+// start returns the commands that run the job and follow its progress.
+func (j *job) start(open OpenFunc) tea.Cmd {
+	return tea.Batch(j.run(open), j.listen())
+}
+
+// Add adds a tab for a file that is already open.
+func (t *Tabs) Add(m Model, spec adapter.OpenSpec) {
+	m.name = fileName(spec.Path)
+	t.tabs = append(t.tabs, m)
+	t.files = append(t.files, spec)
+	t.jobs = append(t.jobs, nil)
+}
+
+// Load adds a tab for a file to open in the background once the program
+// starts, showing the progress of the index build if one is needed.
+func (t *Tabs) Load(spec adapter.OpenSpec) {
+	t.nextJob++
+	t.tabs = append(t.tabs, Model{})
+	t.files = append(t.files, spec)
+	t.jobs = append(t.jobs, newJob(t.nextJob, spec))
+}
+
+// load adds a tab for a file, shows it and starts opening the file.
+func (t *Tabs) load(spec adapter.OpenSpec) tea.Cmd {
+	t.Load(spec)
+	t.active = len(t.tabs) - 1
+	return tea.Batch(t.jobs[t.active].start(t.open), t.resize())
+}
+
+// loading reports whether the file of a tab is still being opened.
+func (t Tabs) loading(i int) bool {
+	return t.jobs[i] != nil
+}
+
+// jobTab returns the tab of a job, or -1 once it is gone.
+func (t Tabs) jobTab(id int) int {
+	return slices.IndexFunc(t.jobs, func(j *job) bool { return j != nil && j.id == id })
+}
+
+// updateProgress records the progress of a job and waits for the next one.
+func (t *Tabs) updateProgress(msg progressMsg) tea.Cmd {
+	i := t.jobTab(msg.id)
+	if i < 0 {
+		return nil
+	}
+	t.jobs[i].progress = msg.progress
+	return t.jobs[i].listen()
+}
+
+// finishJob puts the viewer of an opened file in its tab. A file that could
+// not be opened closes its tab; when that was the last one, the program
+// ends and the error is left for Err.
+func (t *Tabs) finishJob(msg fileOpenedMsg) tea.Cmd {
+	i := t.jobTab(msg.id)
+	if i < 0 {
+		return nil
+	}
+	if msg.err == nil {
+		t.tabs[i] = msg.model
+		t.tabs[i].name = fileName(msg.spec.Path)
+		t.jobs[i] = nil
+		return tea.Batch(t.resize(), wrapCmd(i, t.tabs[i].Init()))
+	}
+
+	t.closeTab(i)
+	canceled := errors.Is(msg.err, context.Canceled)
+	if len(t.tabs) == 0 {
+		if !canceled {
+			t.err = fmt.Errorf("%s: %w", msg.spec.Path, msg.err)
+		}
+		return tea.Quit
+	}
+	n := notice{time: time.Now(), level: adapter.LevelError, text: fmt.Sprintf("Could not open %s: %v", msg.spec.Path, msg.err)}
+	if canceled {
+		n = notice{time: time.Now(), level: adapter.LevelInfo, text: "Cancelled opening " + msg.spec.Path}
+	}
+	return tea.Batch(t.addNotice(n), t.resize())
+}
+
+// closeTab removes a tab, showing its neighbour if it was the active one.
+func (t *Tabs) closeTab(i int) {
+	t.tabs = slices.Delete(t.tabs, i, i+1)
+	t.files = slices.Delete(t.files, i, i+1)
+	t.jobs = slices.Delete(t.jobs, i, i+1)
+	if t.active > i || t.active >= len(t.tabs) {
+		t.active = max(0, t.active-1)
+	}
+}
+
+// Err returns why the program ended without a file open, if it did.
+func (t Tabs) Err() error { return t.err }
+
+// updateJob handles a key on a tab whose file is being opened.
+func (t *Tabs) updateJob(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, loadCancel):
+		t.jobs[t.active].cancel()
+	case key.Matches(msg, t.keys.Quit):
+		for _, j := range t.jobs {
+			if j != nil {
+				j.cancel()
+			}
+		}
+		return tea.Quit
+	}
+	return nil
+}
+
+// renderJob renders a tab whose file is being opened: its progress bar, or
+// a plain message until progress is reported, and its help line.
+func (t Tabs) renderJob(j *job) string {
+	lines := []string{t.styles.Highlight.Bold(true).Render("Opening " + fileName(j.spec.Path))}
+	p := j.progress
+	if p.Task != "" {
+		lines = append(lines, "", p.Task+"...")
+	}
+	if f := p.Fraction(); f >= 0 {
+		width := min(progressWidth, t.width-10)
+		filled := int(f * float64(width))
+		bar := t.styles.Highlight.Render(strings.Repeat("█", filled)) + t.styles.Status.Render(strings.Repeat("░", width-filled))
+		lines = append(lines, fmt.Sprintf("%s %3.0f%%", bar, f*100),
+			fmt.Sprintf("%s of %s", formatSize(p.Done), formatSize(p.Total)))
+	}
+	if p.Detail != "" {
+		lines = append(lines, p.Detail)
+	}
+	lines = append(lines, t.styles.Status.Render(fmt.Sprintf("%s elapsed", time.Since(j.started).Round(time.Second))))
+
+	height := t.height - t.barHeight() - footerHeight
+	body := lipgloss.Place(t.width, height, lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, lines...))
+	bindings := []key.Binding{loadCancel, t.keys.NextTab, t.keys.MessageLog, t.keys.Quit}
+	footer := lipgloss.NewStyle().MaxWidth(t.width).Render(t.picker.help.ShortHelpView(bindings))
+	return lipgloss.JoinVertical(lipgloss.Left, body, footer)
+}
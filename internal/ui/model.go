@@ -7,11 +7,18 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bookmark"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/summary"
 )
 
 type focusState int
@@ -19,8 +26,13 @@ type focusState int
 const (
 	focusList focusState = iota
 	focusViewport
+	focusGaps
+	focusBookmarks
 )
 
+// footerHeight is the number of lines used by the help footer.
+const footerHeight = 1
+
 // item represents a single sequence in our list. It needs to satisfy
 // the list.Item interface for the bubbles/list component.
 type item struct {
@@ -38,50 +50,148 @@ func (i item) FilterValue() string { return i.symbol.Name }
 
 // Model holds the state of our TUI application.
 type Model struct {
-	adapter      adapter.Reader // Store the adapter to fetch data
-	list         list.Model
-	viewport     viewport.Model // For the sequence viewer
-	styles       Styles
-	focus        focusState
-	currentSlice adapter.Slice
-	quitting     bool
-	width        int
-	height       int
+	adapter       adapter.Reader // Store the adapter to fetch data
+	list          list.Model
+	viewport      viewport.Model // For the sequence viewer
+	styles        Styles
+	keys          KeyMap
+	help          help.Model
+	showHelp      bool // Show the full help overlay
+	layout        config.Layout
+	theme         config.Theme // Colors of figures, which the styles cannot give back
+	name          string       // Name of the file, set by the tabs
+	focus         focusState
+	currentSlice  adapter.Slice
+	sliceStart    int64  // 0-based position of the first base in currentSlice
+	ref           string // Name of the sequence in currentSlice
+	refLength     int64  // Length of that sequence, of which currentSlice may be a window
+	window        int64  // Bases read at once from remote files; 0 reads whole sequences
+	windowCmd     tea.Cmd
+	lineWidth     int    // Bases per wrapped sequence row
+	marginWidth   int    // Width of the coordinate margin
+	cursor        int64  // 0-based offset of the cursor in currentSlice
+	topRow        int64  // First wrapped row shown in the viewport
+	pendingTop    *int64 // First base to show once the rows are laid out, from a session
+	visibleRows   int    // Number of rows shown in the last render
+	zoom          int    // Index into zoomLevels
+	selecting     bool   // Visual selection mode is on
+	anchor        int64  // Offset where the selection started
+	dragging      bool   // A mouse drag over the sequence is in progress
+	selStats      map[string]string
+	lineRows      []int64 // Wrapped row drawn on each viewport line
+	pyramid       *summary.Pyramid
+	gaps          list.Model
+	marks         list.Model      // Bookmark panel
+	bookmarks     *bookmark.Store // Bookmarks and jump history of the file
+	showBookmarks bool
+	editing       bookmarkField // Bookmark field being edited, if any
+	input         textinput.Model
+	showGaps      bool
+	showSoftMask  bool
+	uppercase     bool // Display soft-masked bases in uppercase
+	seqType       fasta.SequenceType
+	ntPalettes    []Palette // Palettes for DNA and RNA
+	aaPalettes    []Palette // Palettes for proteins
+	ntPalette     int       // Index of the active nucleotide palette
+	aaPalette     int       // Index of the active protein palette
+	showList      bool      // The sequence list pane is open
+	showStats     bool      // The stats pane is open
+	stack         stackMode // Whether the list and the viewer take turns at the screen
+	listWidth     int       // List width set by the user, or 0 to follow the terminal
+	statsHeight   int       // Height of the stats pane below the viewer
+	geo           geometry  // Pane sizes for the terminal size
+	quitting      bool
+	width         int
+	height        int
 }
 
-// NewModel creates and returns a new TUI model, initialized with the sequence symbols.
-func NewModel(symbols []adapter.Symbol, reader adapter.Reader) Model {
+// NewModel creates and returns a new TUI model, initialized with the sequence
+// symbols and the user configuration.
+func NewModel(symbols []adapter.Symbol, reader adapter.Reader, cfg config.Config, store *bookmark.Store) Model {
+	styles := NewStyles(cfg.Theme) // Initialize styles
+
 	// 1. Convert our []adapter.Symbol into a []list.Item for the component.
-	items := make([]list.Item, len(symbols))
-	for i, sym := range symbols {
-		items[i] = item{symbol: sym}
-	}
+	items := symbolItems(symbols)
 
 	// 2. Setup the list component.
-	ls := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	ls := list.New(items, styles.ListDelegate(), 0, 0)
 	ls.Title = "Fasta Sequences"
 	ls.SetShowStatusBar(true)
 	ls.SetFilteringEnabled(true)
+	// Quitting and help are handled by the model so that they follow the configured keys.
+	ls.SetShowHelp(false)
+	ls.KeyMap.Quit.SetEnabled(false)
+	ls.KeyMap.ForceQuit.SetEnabled(false)
+	ls.KeyMap.ShowFullHelp.SetEnabled(false)
+	ls.KeyMap.CloseFullHelp.SetEnabled(false)
 
 	// The viewport is not visible yet, but we initialize it.
+	// Line-by-line and horizontal movement belong to the base cursor instead.
 	vp := viewport.New(0, 0)
+	vp.KeyMap.Up.SetEnabled(false)
+	vp.KeyMap.Down.SetEnabled(false)
+	vp.KeyMap.Left.SetEnabled(false)
+	vp.KeyMap.Right.SetEnabled(false)
+
+	m := Model{
+		adapter:      reader,
+		list:         ls,
+		viewport:     vp,
+		gaps:         newGapList(styles),
+		marks:        newBookmarkList(styles),
+		bookmarks:    store,
+		input:        newBookmarkInput(styles),
+		styles:       styles,
+		keys:         NewKeyMap(cfg.Keys),
+		help:         newHelp(styles),
+		layout:       cfg.Layout,
+		theme:        cfg.Theme,
+		focus:        focusList, // <-- Start with the list focused
+		showGaps:     cfg.Display.ShowGaps,
+		showSoftMask: cfg.Display.SoftMaskLane,
+		uppercase:    cfg.Display.Uppercase,
+		showList:     true,
+		showStats:    true,
+		statsHeight:  cfg.Layout.StatsHeight,
+	}
+
+	// Only offer the actions the adapter can perform.
+	m.keys.applyCapabilities(reader.Capabilities())
+	m.showGaps = m.showGaps && m.keys.ToggleGaps.Enabled()
+	if r, ok := reader.(adapter.Remote); ok && r.Remote() {
+		m.window = remoteWindow
+	}
 
-	return Model{
-		adapter:  reader,
-		list:     ls,
-		viewport: vp,
-		styles:   NewStyles(), // Initialize styles
-		focus:    focusList,   // <-- Start with the list focused
+	// Fall back to the monochrome palettes (always last) without color support.
+	m.ntPalettes = newNucleotidePalettes(m.styles.SoftMask, cfg.Theme.Bases)
+	m.aaPalettes = newProteinPalettes(m.styles.SoftMask)
+	m.ntPalette = paletteIndex(m.ntPalettes, cfg.Display.Palette)
+	m.aaPalette = paletteIndex(m.aaPalettes, cfg.Display.ProteinPalette)
+	if !hasColor() {
+		m.ntPalette = len(m.ntPalettes) - 1
+		m.aaPalette = len(m.aaPalettes) - 1
 	}
+	m.updateBookmarkList()
+	return m
 }
 
-// Init is the first command that's run when the program starts.
+// Init is the first command that's run when the viewer starts: it follows
+// the records of a stream as they arrive.
 func (m Model) Init() tea.Cmd {
-	return nil // No initial command needed.
+	return m.followStream()
 }
 
 // Update is the main event loop. It handles messages and updates the model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if m, ok := next.(Model); ok && m.windowCmd != nil {
+		return m, tea.Batch(cmd, m.takeWindowCmd())
+	}
+	return next, cmd
+}
+
+// update handles a message for Update.
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
@@ -89,62 +199,176 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.resize()
+		return m, nil
 
-		// Get styles and their overhead
-		var listStyle, viewportStyle lipgloss.Style
-		if m.focus == focusList {
-			listStyle = m.styles.Active
-			viewportStyle = m.styles.Inactive
-		} else {
-			listStyle = m.styles.Inactive
-			viewportStyle = m.styles.Active
+	case viewExportedMsg:
+		if msg.err != nil {
+			return m, notify(adapter.LevelError, "Export failed: %v", msg.err)
+		}
+		return m, notify(adapter.LevelInfo, "Saved the view to %s", msg.path)
+
+	case bedExportedMsg:
+		// Report in the focused panel, or in the list.
+		status := m.list.NewStatusMessage
+		switch m.focus {
+		case focusGaps:
+			status = m.gaps.NewStatusMessage
+		case focusBookmarks:
+			status = m.marks.NewStatusMessage
+		}
+		if msg.err != nil {
+			return m, notify(adapter.LevelError, "Export failed: %v", msg.err)
 		}
+		return m, status(fmt.Sprintf("Wrote %d intervals to %s", msg.count, msg.path))
 
-		// Overheads (borders + padding + margins)
-		listH := listStyle.GetHorizontalFrameSize()
-		listV := listStyle.GetVerticalFrameSize()
-		vpH := viewportStyle.GetHorizontalFrameSize()
-		vpV := viewportStyle.GetVerticalFrameSize()
-
-		// Layout
-		listPaneWidth := m.width / 3
-		rightPaneWidth := m.width - listPaneWidth
-		statsPaneHeight := 7
-
-		// Size list (subtract both H and V frames)
-		m.list.SetSize(
-			listPaneWidth-listH,
-			m.height-listV, // <-- was m.height-2
+	case bookmarksImportedMsg:
+		if msg.err != nil {
+			return m, notify(adapter.LevelError, "Import failed: %v", msg.err)
+		}
+		for _, b := range msg.bookmarks {
+			m.bookmarks.Add(b)
+		}
+		return m, tea.Batch(
+			m.updateBookmarkList(),
+			m.saveBookmarks(),
+			m.marks.NewStatusMessage(fmt.Sprintf("Imported %d bookmarks from %s", len(msg.bookmarks), msg.path)),
 		)
 
-		// Size viewport (subtract both H and V frames)
-		m.viewport.Width = rightPaneWidth - vpH
-		m.viewport.Height = m.height - statsPaneHeight - vpV // <-- was ...-2
-
-		// Re-wrap the sequence with the new viewport width
-		if m.currentSlice.Sequence != nil {
-			wrapped := m.wrapSequence(string(m.currentSlice.Sequence), 1)
-			m.viewport.SetContent(wrapped)
+	case tea.MouseMsg:
+		if m.showHelp {
+			return m, nil
 		}
-		return m, nil
+		return m, m.handleMouse(msg)
+
+	case streamMsg:
+		return m, m.updateStream(msg)
+
+	case copiedMsg:
+		copied := fmt.Sprintf("Copied %s as %s", formatBases(msg.bases), msg.format)
+		switch {
+		case msg.err != nil:
+			return m, notify(adapter.LevelError, "Copy failed: %v", msg.err)
+		case msg.localErr == errNoClipboard:
+			return m, m.list.NewStatusMessage(copied + " through the terminal")
+		case msg.localErr != nil:
+			return m, notify(adapter.LevelWarn, "%s through the terminal only, as the local clipboard failed: %v", copied, msg.localErr)
+		}
+		return m, m.list.NewStatusMessage(copied)
 
 	// Handle key presses.
 	case tea.KeyMsg:
-
-		switch msg.String() {
-		case "ctrl+c", "q":
-			m.quitting = true
-			return m, tea.Quit
-
-		case "tab":
-			// Switch focus between the list and the viewport.
-			if m.focus == focusList {
-				m.focus = focusViewport
-			} else {
-				m.focus = focusList
+		// The help overlay swallows every key until it is closed.
+		if m.showHelp {
+			switch {
+			case key.Matches(msg, m.keys.Quit):
+				return m, m.quit()
+			case key.Matches(msg, m.keys.Help), msg.String() == "esc":
+				m.showHelp = false
 			}
 			return m, nil
 		}
+
+		// The bookmark editor takes every key until it is closed.
+		if m.editing != editNone {
+			return m, m.updateEdit(msg)
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, m.quit()
+
+		case key.Matches(msg, m.keys.FocusNext):
+			// Cycle focus between the list, the viewport and the open panels.
+			m.focusNext()
+			return m, nil
+		}
+
+		// Letter keys are left to the list while its filter is being typed.
+		if m.list.FilterState() != list.Filtering {
+			switch {
+			case key.Matches(msg, m.keys.Help):
+				m.showHelp = true
+				return m, nil
+
+			case key.Matches(msg, m.keys.ToggleGaps):
+				// Toggle the N-run panel and focus it when it opens.
+				m.showGaps = !m.showGaps
+				if m.showGaps {
+					m.focus = focusGaps
+				} else if m.focus == focusGaps {
+					m.focus = focusViewport
+				}
+				m.resize()
+				return m, nil
+
+			case key.Matches(msg, m.keys.ToggleBookmarks):
+				// Toggle the bookmark panel and focus it when it opens.
+				m.showBookmarks = !m.showBookmarks
+				if m.showBookmarks {
+					m.focus = focusBookmarks
+				} else if m.focus == focusBookmarks {
+					m.focus = focusViewport
+				}
+				m.resize()
+				return m, nil
+
+			case key.Matches(msg, m.keys.ToggleList):
+				m.toggleList()
+				return m, nil
+
+			case key.Matches(msg, m.keys.ToggleStats):
+				m.toggleStats()
+				return m, nil
+
+			case key.Matches(msg, m.keys.ToggleStacked):
+				m.toggleStacked()
+				return m, nil
+
+			case key.Matches(msg, m.keys.GrowList):
+				m.resizeList(listStep)
+				return m, nil
+
+			case key.Matches(msg, m.keys.ShrinkList):
+				m.resizeList(-listStep)
+				return m, nil
+
+			case key.Matches(msg, m.keys.GrowStats):
+				m.resizeStats(1)
+				return m, nil
+
+			case key.Matches(msg, m.keys.ShrinkStats):
+				m.resizeStats(-1)
+				return m, nil
+
+			case key.Matches(msg, m.keys.AddBookmark):
+				return m, m.addBookmark()
+
+			case key.Matches(msg, m.keys.HistoryBack):
+				return m, m.historyBack()
+
+			case key.Matches(msg, m.keys.HistoryForward):
+				return m, m.historyForward()
+
+			case key.Matches(msg, m.keys.ToggleSoftMask):
+				// Toggle the soft-masked lane and its runs in the gap panel.
+				m.showSoftMask = !m.showSoftMask
+				m.refresh()
+				return m, m.updateGapList()
+
+			case key.Matches(msg, m.keys.CyclePalette):
+				// Cycle the base coloring palette for the current sequence type.
+				name := m.cyclePalette()
+				m.refresh()
+				return m, m.list.NewStatusMessage("Palette: " + name)
+
+			case key.Matches(msg, m.keys.ToggleUpper):
+				// Toggle uppercasing of soft-masked bases in the viewer.
+				m.uppercase = !m.uppercase
+				m.refresh()
+				return m, nil
+			}
+		}
 	}
 
 	// --- Component-Specific Message Routing ---
@@ -154,18 +378,165 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case focusList:
 		// The list is focused.
 		beforeIndex := m.list.Index()
+		from := m.location()
 		m.list, cmd = m.list.Update(msg)
 		if m.list.Index() != beforeIndex {
 			// The selection changed, so update the viewport content.
-			return m, m.updateViewportContent()
+			cmd = m.updateViewportContent()
+			m.recordJump(from)
+			return m, cmd
 		}
 	case focusViewport:
-		// The viewport is focused, so only it should receive updates.
-		m.viewport, cmd = m.viewport.Update(msg)
+		// The viewport is focused: the cursor keys move the base cursor (one
+		// column or row at the current zoom) and the page keys scroll the rows.
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			page := int64(m.visibleRows)
+			switch {
+			case key.Matches(keyMsg, m.keys.CursorLeft):
+				m.moveCursor(-m.binSize())
+			case key.Matches(keyMsg, m.keys.CursorRight):
+				m.moveCursor(m.binSize())
+			case key.Matches(keyMsg, m.keys.CursorUp):
+				m.moveCursor(-m.rowSpan())
+			case key.Matches(keyMsg, m.keys.CursorDown):
+				m.moveCursor(m.rowSpan())
+			case key.Matches(keyMsg, m.viewport.KeyMap.PageUp):
+				m.scrollRows(-page)
+			case key.Matches(keyMsg, m.viewport.KeyMap.PageDown):
+				m.scrollRows(page)
+			case key.Matches(keyMsg, m.viewport.KeyMap.HalfPageUp):
+				m.scrollRows(-max(1, page/2))
+			case key.Matches(keyMsg, m.viewport.KeyMap.HalfPageDown):
+				m.scrollRows(max(1, page/2))
+			case key.Matches(keyMsg, m.keys.ZoomIn):
+				m.zoomBy(-1)
+			case key.Matches(keyMsg, m.keys.ZoomOut):
+				m.zoomBy(1)
+			case key.Matches(keyMsg, m.keys.Visual):
+				m.toggleSelection()
+			case keyMsg.String() == "esc":
+				m.clearSelection()
+			case key.Matches(keyMsg, m.keys.Copy):
+				return m, m.copySelection(copyRaw)
+			case key.Matches(keyMsg, m.keys.CopyFASTA):
+				return m, m.copySelection(copyFASTA)
+			case key.Matches(keyMsg, m.keys.CopyRevComp):
+				return m, m.copySelection(copyRevComp)
+			case key.Matches(keyMsg, m.keys.ExportSelection):
+				return m, m.exportSelection()
+			case key.Matches(keyMsg, m.keys.ExportPNG):
+				return m, m.exportFigure()
+			case key.Matches(keyMsg, m.keys.ExportJSON):
+				return m, m.exportJSON()
+			}
+		}
+	case focusGaps:
+		// The gap panel is focused: enter jumps to the run, b exports BED.
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(keyMsg, m.keys.Jump):
+				m.jumpToRun()
+				return m, nil
+			case key.Matches(keyMsg, m.keys.ExportBED):
+				return m, exportRuns(m.adapter, m.symbolNames(), m.visibleTracks(), m.name)
+			}
+		}
+		beforeIndex := m.gaps.Index()
+		m.gaps, cmd = m.gaps.Update(msg)
+		if m.gaps.Index() != beforeIndex {
+			m.jumpToRun()
+		}
+	case focusBookmarks:
+		// The bookmark panel is focused: enter jumps to the bookmark.
+		if m.editing != editNone {
+			return m, m.updateEdit(msg)
+		}
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(keyMsg, m.keys.Jump):
+				return m, m.jumpToBookmark()
+			case key.Matches(keyMsg, m.keys.RenameBookmark):
+				return m, m.startEdit(editName)
+			case key.Matches(keyMsg, m.keys.EditNote):
+				return m, m.startEdit(editNote)
+			case key.Matches(keyMsg, m.keys.DeleteBookmark):
+				return m, m.deleteBookmark()
+			case key.Matches(keyMsg, m.keys.ImportBookmarks):
+				return m, m.startImport()
+			case key.Matches(keyMsg, m.keys.ExportBookmarks):
+				return m, exportBookmarks(m.bookmarks.Bookmarks, m.name)
+			}
+		}
+		m.marks, cmd = m.marks.Update(msg)
 	}
 	return m, cmd
 }
 
+// quit ends the program. The jump history is saved by the caller, once the
+// program has exited.
+func (m *Model) quit() tea.Cmd {
+	m.quitting = true
+	return tea.Quit
+}
+
+// Bookmarks returns the bookmarks and jump history of the file.
+func (m Model) Bookmarks() *bookmark.Store { return m.bookmarks }
+
+// Close releases the adapter of the file.
+func (m Model) Close() error { return m.adapter.Close() }
+
+// capturesKeys reports whether the model is taking every key for itself:
+// the help overlay, the bookmark editor or the list filter is open.
+func (m Model) capturesKeys() bool {
+	return m.showHelp || m.editing != editNone || m.list.FilterState() == list.Filtering
+}
+
+// focusNext moves the focus to the next visible pane. When the list and the
+// viewer are stacked, this is also how to switch between them.
+func (m *Model) focusNext() {
+	var panes []focusState
+	if m.showList {
+		panes = append(panes, focusList)
+	}
+	panes = append(panes, focusViewport)
+	if m.showGaps {
+		panes = append(panes, focusGaps)
+	}
+	if m.showBookmarks {
+		panes = append(panes, focusBookmarks)
+	}
+	next := 0
+	for i, pane := range panes {
+		if pane == m.focus {
+			next = (i + 1) % len(panes)
+		}
+	}
+	m.focus = panes[next]
+	if m.stacked() {
+		m.resize()
+	}
+}
+
+// paneStyle returns the border style for a pane, highlighting the focused one.
+func (m Model) paneStyle(pane focusState) lipgloss.Style {
+	if m.focus == pane {
+		return m.styles.Active
+	}
+	return m.styles.Inactive
+}
+
+// symbolNames returns the names of all sequences in list order.
+func (m Model) symbolNames() []string {
+	items := m.list.Items()
+	names := make([]string, 0, len(items))
+	for _, it := range items {
+		if i, ok := it.(item); ok {
+			names = append(names, i.symbol.Name)
+		}
+	}
+	return names
+}
+
 // view renders de TUI.
 func (m Model) View() string {
 	if m.quitting {
@@ -174,72 +545,53 @@ func (m Model) View() string {
 	if m.width == 0 {
 		return "Initializing..."
 	}
+	if m.showHelp {
+		return m.renderHelpOverlay()
+	}
+	if m.geo.tooSmall {
+		return m.renderTooSmall()
+	}
 
 	// --- Dynamic Style Assignment ---
-	var listStyle, viewportStyle lipgloss.Style
-	if m.focus == focusList {
-		listStyle = m.styles.Active
-		viewportStyle = m.styles.Inactive
-	} else {
-		listStyle = m.styles.Inactive
-		viewportStyle = m.styles.Active
-	}
+	listStyle := m.paneStyle(focusList)
+	viewportStyle := m.paneStyle(focusViewport)
 
 	// --- RENDER PANES ---
 	// NOTE: All sizing logic has been removed from here.
-	listView := listStyle.Render(m.list.View())
-	viewportView := viewportStyle.Render(m.viewport.View())
-	statsView := m.renderStatsPanel()
-
-	// --- ASSEMBLE FINAL VIEW ---
-	rightPane := lipgloss.JoinVertical(lipgloss.Top, viewportView, statsView)
-	return lipgloss.JoinHorizontal(lipgloss.Top, listView, rightPane)
-}
-
-// wrapSequence wraps a DNA sequence to fit within the viewport width,
-// prepending each line with its genomic coordinate.
-func (m *Model) wrapSequence(sequence string, startCoord int) string {
-	if m.viewport.Width <= 0 {
-		return sequence
+	var columns []string
+	if m.geo.listWidth > 0 {
+		// Pad the list to its full width so the panes line up with the mouse geometry.
+		columns = append(columns, listStyle.Width(m.list.Width()+listStyle.GetHorizontalPadding()).Render(m.list.View()))
 	}
 
-	// 1. Get the style that will be used for the viewport pane.
-	style := m.styles.Inactive
-
-	// 2. Ask the style for its horizontal padding.
-	padding := style.GetHorizontalPadding()
-	// Define the width of our position indicator margin (e.g., "1234567890 ").
-	marginWidth := 11
-
-	// The space available for the sequence is the viewport's inner width minus our margin.
-	availableWidth := m.viewport.Width - padding
-	lineWidth := availableWidth - marginWidth
-
-	if lineWidth <= 0 {
-		return sequence
-	}
-
-	var wrapped strings.Builder
-	currentCoord := startCoord
-	for i := 0; i < len(sequence); i += lineWidth {
-		end := min(i+lineWidth, len(sequence))
-
-		if i > 0 {
-			wrapped.WriteString("\n")
+	// --- ASSEMBLE FINAL VIEW ---
+	if m.geo.mainWidth > 0 {
+		viewportView := viewportStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+			m.renderRuler(),
+			m.viewport.View(),
+			m.renderStatus(),
+		))
+		rightPanes := []string{viewportView}
+		if m.showGaps {
+			rightPanes = append(rightPanes, m.renderPanel(focusGaps, m.gaps.View()))
+		}
+		if m.showBookmarks {
+			marksView := m.marks.View()
+			if m.editing != editNone {
+				marksView = lipgloss.JoinVertical(lipgloss.Left, marksView, m.input.View())
+			}
+			rightPanes = append(rightPanes, m.renderPanel(focusBookmarks, marksView))
+		}
+		if m.geo.statsHeight > 0 {
+			rightPanes = append(rightPanes, m.renderStatsPanel())
+		}
+		columns = append(columns, lipgloss.JoinVertical(lipgloss.Top, rightPanes...))
+		if m.geo.statsWidth > 0 {
+			columns = append(columns, m.renderStatsPanel())
 		}
-
-		// Prepend the formatted coordinate.
-		// The `%-10d` format right-pads the number with spaces to a width of 10.
-		coordMargin := fmt.Sprintf("%-10d", currentCoord)
-		wrapped.WriteString(coordMargin)
-
-		wrapped.WriteString(sequence[i:end])
-
-		// Increment our coordinate for the next line.
-		currentCoord += lineWidth
 	}
-
-	return wrapped.String()
+	panes := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	return lipgloss.JoinVertical(lipgloss.Left, panes, m.renderFooter())
 }
 
 // updateViewportContent is a new helper function to fetch and set the viewport data.
@@ -250,31 +602,58 @@ func (m *Model) updateViewportContent() tea.Cmd {
 		return nil
 	}
 
-	// Use the adapter to fetch the full sequence.
-	region := adapter.Region{Ref: selectedItem.symbol.Name, Start: 1, End: selectedItem.symbol.Length}
+	// Use the adapter to fetch the full sequence, or its first window.
+	region := m.symbolRegion(selectedItem.symbol)
 	slice, err := m.adapter.Region(region)
 	if err != nil {
-		m.viewport.SetContent(fmt.Sprintf("Error: %v", err))
-		return nil
+		// Leave the viewer empty rather than showing the previous sequence.
+		m.currentSlice = adapter.Slice{}
+		m.ref = region.Ref
+		m.pyramid = nil
+		m.selecting = false
+		m.refresh()
+		return notify(adapter.LevelError, "Could not read %s: %v", region.Ref, err)
 	}
 
 	// 1. Store the entire generic Slice object in model
 	m.currentSlice = slice
-
-	// 2. Wrap and set the content from the slice's Sequence field.
-	wrappedSequence := m.wrapSequence(string(m.currentSlice.Sequence), 1)
-	m.viewport.SetContent(wrappedSequence)
-
-	// Go back to the top of the viewport every time the content changes.
-	m.viewport.GotoTop()
-
-	return nil
+	m.ref = region.Ref
+	m.refLength = selectedItem.symbol.Length
+	m.windowStats(slice, region)
+	m.sliceStart = region.Start - 1
+	m.seqType = fasta.InferSequenceType(slice.Sequence)
+	m.pyramid = nil
+
+	// 2. Go back to the top of the viewer every time the content changes,
+	// and render the visible rows.
+	m.cursor = 0
+	m.topRow = 0
+	m.selecting = false
+	m.dragging = false
+	m.refresh()
+
+	return m.updateGapList()
 }
 
 func (m Model) renderStatsPanel() string {
 	style := m.styles.Inactive
 
+	// Below the viewer, the pane spans the viewer column. Beside it, the pane
+	// takes the full height.
+	height, width := m.geo.statsHeight, m.viewerWidth()
+	if m.geo.statsWidth > 0 {
+		height, width = m.height-footerHeight, m.geo.statsWidth
+	}
+	style = style.
+		Width(width - style.GetHorizontalBorderSize()).
+		Height(height - style.GetVerticalBorderSize())
+	width -= style.GetHorizontalFrameSize()
+
+	// A selection replaces the stats of the whole sequence.
 	stats := m.currentSlice.Stats
+	if m.selecting {
+		stats = m.selStats
+	}
 	if len(stats) == 0 {
 		return style.Render("")
 	}
@@ -288,18 +667,33 @@ func (m Model) renderStatsPanel() string {
 	// Sort the keys alphabetically for a consistent order.
 	sort.Strings(keys)
 
-	// Build the content string by iterating over the sorted keys.
-	var contentBuilder strings.Builder
-	for _, key := range keys {
-		value := stats[key]
-		// Left-align the key, right-align the value.
-		line := lipgloss.JoinHorizontal(lipgloss.Left,
-			fmt.Sprintf("%-12s", key), // Pad the key for alignment
-			lipgloss.NewStyle().Width(m.viewport.Width-12).Align(lipgloss.Right).Render(value),
-		)
-		contentBuilder.WriteString(line)
-		contentBuilder.WriteString("\n")
+	// Lay the stats out in as many columns as needed to fit the pane height.
+	rowsPerColumn := max(1, height-style.GetVerticalFrameSize())
+	columns := (len(keys) + rowsPerColumn - 1) / rowsPerColumn
+	columnWidth := width / columns
+	gap := 0
+	if columns > 1 {
+		gap = 2 // Keep adjacent columns apart
+	}
+
+	// Build each column by iterating over the sorted keys.
+	views := make([]string, 0, columns)
+	for c := 0; c < columns; c++ {
+		var contentBuilder strings.Builder
+		for _, key := range keys[c*rowsPerColumn : min((c+1)*rowsPerColumn, len(keys))] {
+			value := stats[key]
+			// Left-align the key, right-align the value.
+			line := lipgloss.JoinHorizontal(lipgloss.Left,
+				fmt.Sprintf("%-12s", key), // Pad the key for alignment
+				lipgloss.NewStyle().Width(columnWidth-12-gap).Align(lipgloss.Right).Render(value),
+				strings.Repeat(" ", gap),
+			)
+			contentBuilder.WriteString(line)
+			contentBuilder.WriteString("\n")
+		}
+		views = append(views, strings.TrimSuffix(contentBuilder.String(), "\n"))
 	}
 
-	return style.Render(strings.TrimSpace(contentBuilder.String()))
+	content := lipgloss.JoinHorizontal(lipgloss.Top, views...)
+	return style.Render(strings.TrimSpace(content))
 }
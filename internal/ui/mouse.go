@@ -0,0 +1,165 @@
+// This file defines how mouse clicks, drags and the wheel map onto the panes.
+
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// wheelRows is the number of rows scrolled per wheel step.
+const wheelRows = 3
+
+// handleMouse routes a mouse event to the pane under the pointer. A drag that
+// started on the sequence keeps extending the selection wherever it goes.
+func (m *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.dragging {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			m.dragTo(msg.X, msg.Y)
+		case tea.MouseActionRelease:
+			m.dragging = false
+		}
+		return nil
+	}
+
+	listWidth := m.listPaneWidth()
+	viewportHeight := m.viewportPaneHeight()
+	switch {
+	case m.geo.tooSmall:
+		return nil
+	case msg.X < listWidth:
+		return m.mouseList(msg)
+	case msg.X >= listWidth+m.viewerWidth():
+		// The stats pane beside the viewer has nothing to click.
+		return nil
+	case msg.Y < viewportHeight:
+		m.mouseViewport(msg)
+	case isLeftPress(msg):
+		// Below the viewport come the open panels, then the stats.
+		y := msg.Y - viewportHeight
+		if m.showGaps {
+			if y < m.layout.GapsHeight {
+				m.focus = focusGaps
+				return nil
+			}
+			y -= m.layout.GapsHeight
+		}
+		if m.showBookmarks && y < m.layout.BookmarksHeight {
+			m.focus = focusBookmarks
+		}
+	}
+	return nil
+}
+
+// mouseList focuses the list and selects the clicked sequence.
+func (m *Model) mouseList(msg tea.MouseMsg) tea.Cmd {
+	if !isLeftPress(msg) || m.list.FilterState() == list.Filtering {
+		return nil
+	}
+	m.focus = focusList
+
+	// Find the item slot under the pointer, skipping the pane frame, the
+	// title and the status bar.
+	style := m.paneStyle(focusList)
+	y := msg.Y - style.GetBorderTopSize() - style.GetPaddingTop() - m.listHeaderHeight()
+	delegate := m.styles.ListDelegate()
+	slot := delegate.Height() + delegate.Spacing()
+	if y < 0 || y%slot >= delegate.Height() {
+		return nil
+	}
+	index := m.list.Paginator.Page*m.list.Paginator.PerPage + y/slot
+	if index >= len(m.list.VisibleItems()) || index == m.list.Index() {
+		return nil
+	}
+	from := m.location()
+	m.list.Select(index)
+	cmd := m.updateViewportContent()
+	m.recordJump(from)
+	return cmd
+}
+
+// listHeaderHeight returns the lines the list draws above its items.
+func (m Model) listHeaderHeight() int {
+	h := 0
+	if m.list.ShowTitle() || m.list.ShowFilter() {
+		h += lipgloss.Height(m.list.Styles.TitleBar.Render(" "))
+	}
+	if m.list.ShowStatusBar() {
+		h += lipgloss.Height(m.list.Styles.StatusBar.Render(" "))
+	}
+	return h
+}
+
+// mouseViewport scrolls with the wheel, and moves the cursor to the clicked
+// base, starting a drag that selects from there.
+func (m *Model) mouseViewport(msg tea.MouseMsg) {
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.scrollRows(-wheelRows)
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.scrollRows(wheelRows)
+	case isLeftPress(msg):
+		m.focus = focusViewport
+		offset, ok := m.offsetAt(msg.X, msg.Y)
+		if !ok {
+			return
+		}
+		m.selecting = false
+		m.setCursor(offset)
+		m.anchor = m.cursor
+		m.dragging = true
+	}
+}
+
+// dragTo extends the selection from the drag anchor to the base under the
+// pointer, scrolling when the pointer leaves the rows.
+func (m *Model) dragTo(x, y int) {
+	top := m.viewportContentTop()
+	switch {
+	case y < top:
+		m.scrollRows(-1)
+		y = top
+	case y >= top+m.viewport.Height:
+		m.scrollRows(1)
+		y = top + m.viewport.Height - 1
+	}
+	offset, ok := m.offsetAt(x, y)
+	if !ok {
+		return
+	}
+	m.selecting = offset != m.anchor
+	m.setCursor(offset)
+}
+
+// offsetAt returns the offset into the current slice of the base (or bin)
+// drawn at a terminal cell, clamping columns to the row.
+func (m Model) offsetAt(x, y int) (int64, bool) {
+	line := y - m.viewportContentTop()
+	if len(m.currentSlice.Sequence) == 0 || line < 0 || line >= len(m.lineRows) {
+		return 0, false
+	}
+	style := m.paneStyle(focusViewport)
+	col := x - m.listPaneWidth() - style.GetBorderLeftSize() - style.GetPaddingLeft() - m.marginWidth
+	col = max(0, min(col, m.lineWidth-1))
+
+	offset := m.lineRows[line]*m.rowSpan() + int64(col)*m.binSize()
+	return min(offset, int64(len(m.currentSlice.Sequence))-1), true
+}
+
+// viewportContentTop returns the terminal line of the first sequence row.
+func (m Model) viewportContentTop() int {
+	style := m.paneStyle(focusViewport)
+	return style.GetBorderTopSize() + style.GetPaddingTop() + rulerHeight
+}
+
+// viewportPaneHeight returns the height of the viewport pane, frame included.
+func (m Model) viewportPaneHeight() int {
+	return m.viewport.Height + rulerHeight + statusHeight + m.paneStyle(focusViewport).GetVerticalFrameSize()
+}
+
+// isLeftPress reports whether the event is a left button press.
+func isLeftPress(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
@@ -0,0 +1,222 @@
+// This file defines the notifications: transient toasts over the screen and
+// the scrollable message log that keeps them.
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// Notification limits.
+const (
+	maxNotices    = 500 // Messages kept in the log
+	maxToasts     = 3   // Toasts shown at once
+	toastWidth    = 48  // Width of a toast, frame included
+	toastDuration = 4 * time.Second
+	errorDuration = 8 * time.Second // Errors stay up longer
+)
+
+// notice is a message for the user.
+type notice struct {
+	time  time.Time
+	level adapter.Level
+	text  string
+}
+
+// noticeMsg carries a notice from a viewer.
+type noticeMsg notice
+
+// noticesMsg carries the notices posted to a Notifier.
+type noticesMsg []notice
+
+// toastExpiredMsg removes a toast once it has been shown long enough.
+type toastExpiredMsg struct {
+	id int
+}
+
+// toast is a notice shown over the screen for a while.
+type toast struct {
+	id int
+	notice
+}
+
+// logClose closes the message log, which is fixed like the list filter keys.
+var logClose = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close"))
+
+// Notifier collects notices from outside the UI: from adapters while files
+// are opened, before the program starts or in the background. It is safe
+// for concurrent use, and never blocks the sender.
+type Notifier struct {
+	mu      sync.Mutex
+	pending []notice
+	wake    chan struct{}
+}
+
+// NewNotifier creates a notifier to pass to NewTabs.
+func NewNotifier() *Notifier {
+	return &Notifier{wake: make(chan struct{}, 1)}
+}
+
+// Notify posts a notice. It satisfies adapter.Notify.
+func (n *Notifier) Notify(level adapter.Level, text string) {
+	n.mu.Lock()
+	n.pending = append(n.pending, notice{time: time.Now(), level: level, text: text})
+	n.mu.Unlock()
+	select {
+	case n.wake <- struct{}{}:
+	default: // A wake-up is already pending
+	}
+}
+
+// wait returns a command that waits for notices and delivers them.
+func (n *Notifier) wait() tea.Cmd {
+	return func() tea.Msg {
+		<-n.wake
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		batch := n.pending
+		n.pending = nil
+		return noticesMsg(batch)
+	}
+}
+
+// notify returns a command that reports a notice from a viewer.
+func notify(level adapter.Level, format string, args ...any) tea.Cmd {
+	return func() tea.Msg {
+		return noticeMsg{time: time.Now(), level: level, text: fmt.Sprintf(format, args...)}
+	}
+}
+
+// addNotice logs a notice and shows it as a toast.
+func (t *Tabs) addNotice(n notice) tea.Cmd {
+	t.notices = append(t.notices, n)
+	if len(t.notices) > maxNotices {
+		t.notices = t.notices[len(t.notices)-maxNotices:]
+	}
+	t.updateLog()
+
+	t.nextToast++
+	t.toasts = append(t.toasts, toast{id: t.nextToast, notice: n})
+	if len(t.toasts) > maxToasts {
+		t.toasts = t.toasts[len(t.toasts)-maxToasts:]
+	}
+	duration := toastDuration
+	if n.level == adapter.LevelError {
+		duration = errorDuration
+	}
+	id := t.nextToast
+	return tea.Tick(duration, func(time.Time) tea.Msg { return toastExpiredMsg{id: id} })
+}
+
+// expireToast removes a toast.
+func (t *Tabs) expireToast(id int) {
+	for i, ts := range t.toasts {
+		if ts.id == id {
+			t.toasts = append(t.toasts[:i], t.toasts[i+1:]...)
+			return
+		}
+	}
+}
+
+// updateLog fills the message log, keeping it scrolled to the bottom unless
+// the user scrolled up.
+func (t *Tabs) updateLog() {
+	atBottom := t.log.AtBottom()
+	lines := make([]string, len(t.notices))
+	for i, n := range t.notices {
+		lines[i] = fmt.Sprintf("%s %s %s", n.time.Format("15:04:05"), t.levelStyle(n.level).Render(fmt.Sprintf("%-5s", n.level)), n.text)
+	}
+	t.log.SetContent(strings.Join(lines, "\n"))
+	if atBottom {
+		t.log.GotoBottom()
+	}
+}
+
+// updateLogPane handles a key while the message log is shown.
+func (t *Tabs) updateLogPane(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, logClose), key.Matches(msg, t.keys.MessageLog):
+		t.showLog = false
+		return nil
+	case key.Matches(msg, t.keys.Quit):
+		return t.updateTab(t.active, msg)
+	}
+	var cmd tea.Cmd
+	t.log, cmd = t.log.Update(msg)
+	return cmd
+}
+
+// levelStyle returns the style of a notice level.
+func (t Tabs) levelStyle(level adapter.Level) lipgloss.Style {
+	switch level {
+	case adapter.LevelError:
+		return t.styles.Error
+	case adapter.LevelWarn:
+		return t.styles.Lane
+	default:
+		return t.styles.Status
+	}
+}
+
+// sizeLog lays out the message log pane.
+func (t *Tabs) sizeLog(width, height int) {
+	style := t.styles.Active
+	t.log.Width = width - style.GetHorizontalFrameSize()
+	t.log.Height = height - footerHeight - style.GetVerticalFrameSize() - 1 // Title line
+	t.updateLog()
+}
+
+// renderLog renders the message log pane with its help line.
+func (t Tabs) renderLog() string {
+	title := t.styles.Highlight.Bold(true).Render(fmt.Sprintf("Messages (%d)", len(t.notices)))
+	body := t.log.View()
+	if len(t.notices) == 0 {
+		body = lipgloss.NewStyle().Height(t.log.Height).Render("No messages yet.")
+	}
+	pane := t.styles.Active.Width(t.width - t.styles.Active.GetHorizontalBorderSize()).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, body))
+	bindings := []key.Binding{t.log.KeyMap.Up, t.log.KeyMap.Down, t.log.KeyMap.PageUp, t.log.KeyMap.PageDown, logClose}
+	footer := lipgloss.NewStyle().MaxWidth(t.width).Render(t.picker.help.ShortHelpView(bindings))
+	return lipgloss.JoinVertical(lipgloss.Left, pane, footer)
+}
+
+// renderToast renders one toast box.
+func (t Tabs) renderToast(ts toast) string {
+	style := t.styles.Inactive.
+		Padding(0, 1).
+		BorderForeground(t.levelStyle(ts.level).GetForeground()).
+		Width(toastWidth - 2)
+	return style.Render(t.levelStyle(ts.level).Render(ts.level.String()) + " " + ts.text)
+}
+
+// overlayToasts draws the toasts over the bottom-right corner of a view,
+// above its help line, newest at the bottom.
+func (t Tabs) overlayToasts(view string) string {
+	if len(t.toasts) == 0 || t.width < toastWidth+minListWidth {
+		return view
+	}
+	boxes := make([]string, len(t.toasts))
+	for i, ts := range t.toasts {
+		boxes[i] = t.renderToast(ts)
+	}
+	overlay := strings.Split(lipgloss.JoinVertical(lipgloss.Right, boxes...), "\n")
+
+	lines := strings.Split(view, "\n")
+	bottom := len(lines) - footerHeight
+	top := max(0, bottom-len(overlay))
+	for i := top; i < bottom; i++ {
+		line := overlay[len(overlay)-(bottom-i)]
+		left := ansi.Truncate(lines[i], t.width-lipgloss.Width(line), "")
+		lines[i] = left + strings.Repeat(" ", max(0, t.width-lipgloss.Width(left)-lipgloss.Width(line))) + line
+	}
+	return strings.Join(lines, "\n")
+}
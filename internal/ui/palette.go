@@ -0,0 +1,216 @@
+// This file defines the color palettes used to paint sequence bases.
+
+package ui
+
+import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/muesli/termenv"
+)
+
+// paint holds the escape sequences that wrap a run of identically styled bases.
+// They are computed once per palette, since styling every base through
+// lipgloss would be far too slow for long sequences.
+type paint struct {
+	prefix string
+	suffix string
+}
+
+// Palette maps each sequence character to a style.
+type Palette struct {
+	Name   string
+	paints []paint         // Distinct paints; index 0 is unstyled
+	index  [256]uint8      // Paint index for every byte value
+	colors map[byte]string // Foreground color of the uppercase bases, for figures
+}
+
+// newPalette builds a palette from groups of characters sharing a style,
+// e.g. "AG" -> purine style. Lowercase (soft-masked) characters get the same
+// style as their uppercase form, combined with the masked style.
+func newPalette(name string, groups map[string]lipgloss.Style, masked lipgloss.Style) Palette {
+	p := Palette{Name: name, paints: []paint{{}}, colors: make(map[byte]string)}
+
+	// Unlisted lowercase characters are still dimmed as soft-masked.
+	maskedOnly := uint8(len(p.paints))
+	p.paints = append(p.paints, newPaint(masked))
+	for c := 'a'; c <= 'z'; c++ {
+		p.index[c] = maskedOnly
+	}
+
+	for chars, style := range groups {
+		upper := uint8(len(p.paints))
+		p.paints = append(p.paints, newPaint(style))
+		lower := uint8(len(p.paints))
+		p.paints = append(p.paints, newPaint(style.Inherit(masked)))
+
+		color, _ := style.GetForeground().(lipgloss.Color)
+		for _, c := range []byte(strings.ToUpper(chars)) {
+			if color != "" {
+				p.colors[c] = string(color)
+			}
+			p.index[c] = upper
+			if c >= 'A' && c <= 'Z' {
+				p.index[c+'a'-'A'] = lower
+			}
+		}
+	}
+	return p
+}
+
+// newPaint extracts the escape sequences a style wraps around its content.
+func newPaint(style lipgloss.Style) paint {
+	const sentinel = "\x00"
+	prefix, suffix, _ := strings.Cut(style.Render(sentinel), sentinel)
+	return paint{prefix: prefix, suffix: suffix}
+}
+
+// fg is a shorthand for a style with only a foreground color.
+func fg(color string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+}
+
+// Nucleotide palettes. Ambiguity codes share one style so that well-defined
+// bases stand out.
+const iupacAmbiguity = "RYSWKMBDHV"
+
+// newNucleotidePalettes returns the palettes offered for DNA and RNA.
+// Colors from the theme's custom base palette replace those of the same
+// bases in the default one; the other bases keep their default colors.
+func newNucleotidePalettes(masked lipgloss.Style, custom map[string]string) []Palette {
+	defaults := map[string]lipgloss.Style{
+		"A":            fg("78"),  // Green
+		"C":            fg("75"),  // Blue
+		"G":            fg("221"), // Yellow
+		"TU":           fg("203"), // Red
+		"N":            fg("244"), // Gray
+		iupacAmbiguity: fg("176"), // Mauve
+		"-":            fg("240"),
+	}
+	for base, color := range custom {
+		base = strings.ToUpper(base)
+		// Take the base out of its default group, such as T out of "TU".
+		for chars, style := range defaults {
+			if strings.Contains(chars, base) {
+				delete(defaults, chars)
+				if rest := strings.ReplaceAll(chars, base, ""); rest != "" {
+					defaults[rest] = style
+				}
+			}
+		}
+		defaults[base] = fg(color)
+	}
+
+	return []Palette{
+		newPalette("default", defaults, masked),
+		// Okabe-Ito colors, distinguishable with the common forms of color blindness.
+		newPalette("okabe-ito", map[string]lipgloss.Style{
+			"A":            fg("#009E73"), // Bluish green
+			"C":            fg("#56B4E9"), // Sky blue
+			"G":            fg("#E69F00"), // Orange
+			"TU":           fg("#D55E00"), // Vermillion
+			"N":            fg("#999999"), // Gray
+			iupacAmbiguity: fg("#CC79A7"), // Reddish purple
+			"-":            fg("#999999"),
+		}, masked),
+		newMonochromePalette(masked),
+	}
+}
+
+// paletteIndex returns the position of the named palette, or 0 if it is unknown.
+func paletteIndex(palettes []Palette, name string) int {
+	for i, p := range palettes {
+		if p.Name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// newProteinPalettes returns the palettes offered for amino acid sequences.
+func newProteinPalettes(masked lipgloss.Style) []Palette {
+	return []Palette{
+		// Clustal X default coloring by physico-chemical class.
+		newPalette("clustal", map[string]lipgloss.Style{
+			"AILMFWV": fg("#80A0F0"), // Hydrophobic
+			"KR":      fg("#F01505"), // Positive charge
+			"ED":      fg("#C048C0"), // Negative charge
+			"NQST":    fg("#15C015"), // Polar
+			"C":       fg("#F08080"), // Cysteine
+			"G":       fg("#F09048"), // Glycine
+			"P":       fg("#C0C000"), // Proline
+			"HY":      fg("#15A4A4"), // Aromatic
+		}, masked),
+		newPalette("zappo", map[string]lipgloss.Style{
+			"ILVAM": fg("#FFAFAF"), // Aliphatic/hydrophobic
+			"FWY":   fg("#FFC800"), // Aromatic
+			"KRH":   fg("#6464FF"), // Positive
+			"DE":    fg("#FF0000"), // Negative
+			"STNQ":  fg("#00FF00"), // Hydrophilic
+			"PG":    fg("#FF00FF"), // Conformationally special
+			"C":     fg("#FFFF00"), // Cysteine
+		}, masked),
+		// Kyte-Doolittle hydrophobicity, from red (hydrophobic) to blue (hydrophilic).
+		newPalette("hydrophobicity", map[string]lipgloss.Style{
+			"I":      fg("#FF0000"),
+			"V":      fg("#F60009"),
+			"L":      fg("#EA0015"),
+			"F":      fg("#CB0034"),
+			"C":      fg("#C2003D"),
+			"M":      fg("#B0004F"),
+			"A":      fg("#AD0052"),
+			"G":      fg("#6A0095"),
+			"X":      fg("#680097"),
+			"T":      fg("#61009E"),
+			"S":      fg("#5E00A1"),
+			"W":      fg("#5B00A4"),
+			"Y":      fg("#4F00B0"),
+			"P":      fg("#4600B9"),
+			"H":      fg("#1500EA"),
+			"EZQDBN": fg("#0C00F3"),
+			"KR":     fg("#0000FF"),
+		}, masked),
+		newMonochromePalette(masked),
+	}
+}
+
+// attributes renders text attributes such as bold even where the terminal
+// reports no color support (the Ascii profile, as with NO_COLOR), which
+// would drop them along with colors.
+var attributes = func() *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.ANSI)
+	return r
+}()
+
+// newMonochromePalette distinguishes bases with text attributes only, for
+// terminals without color support. The styles are rendered with attributes
+// so that the terminal shows them.
+func newMonochromePalette(masked lipgloss.Style) Palette {
+	return newPalette("mono", map[string]lipgloss.Style{
+		"AG":           attributes.NewStyle().Bold(true),      // Purines
+		"N":            attributes.NewStyle().Underline(true), // Unknown
+		iupacAmbiguity: attributes.NewStyle().Italic(true),
+	}, attributes.NewStyle().Inherit(masked))
+}
+
+// BaseColors returns the colors of the bases in the palette the
+// configuration chooses for a type of sequence, for figures drawn outside
+// the viewer.
+func BaseColors(cfg config.Config, seqType fasta.SequenceType) map[byte]string {
+	masked := NewStyles(cfg.Theme).SoftMask
+	if seqType == fasta.Protein {
+		palettes := newProteinPalettes(masked)
+		return palettes[paletteIndex(palettes, cfg.Display.ProteinPalette)].colors
+	}
+	palettes := newNucleotidePalettes(masked, cfg.Theme.Bases)
+	return palettes[paletteIndex(palettes, cfg.Display.Palette)].colors
+}
+
+// hasColor reports whether the terminal can display colors at all.
+func hasColor() bool {
+	return lipgloss.ColorProfile() != termenv.Ascii
+}
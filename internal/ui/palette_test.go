@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/muesli/termenv"
+)
+
+func TestNucleotidePalettes_CustomBase(t *testing.T) {
+	palettes := newNucleotidePalettes(lipgloss.NewStyle(), map[string]string{"A": "#00FF00", "t": "#FF0000"})
+	got := palettes[paletteIndex(palettes, "default")].colors
+	want := map[byte]string{
+		'A': "#00FF00", 'C': "75", 'G': "221", 'T': "#FF0000", 'U': "203", 'N': "244", '-': "240",
+		'R': "176", 'Y': "176", 'S': "176", 'W': "176", 'K': "176",
+		'M': "176", 'B': "176", 'D': "176", 'H': "176", 'V': "176",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("expected the other bases to keep their default colors:\n%v\ngot\n%v", want, got)
+	}
+}
+
+func TestMonochromePalette_Ascii(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.Ascii)
+	defer lipgloss.SetColorProfile(profile)
+
+	m := Model{ntPalettes: []Palette{newMonochromePalette(NewStyles(config.Default().Theme).SoftMask)}}
+	seen := map[string]byte{}
+	for _, base := range []byte("ANRc") {
+		got := m.renderBases(string(base))
+		if got == string(base) {
+			t.Errorf("expected %c to be styled without color support, got %q", base, got)
+		}
+		// Compare the styles alone, whatever the base.
+		style := strings.ReplaceAll(got, string(base), "")
+		if other, ok := seen[style]; ok {
+			t.Errorf("expected %c and %c to be styled differently, both got %q", other, base, style)
+		}
+		seen[style] = base
+	}
+	if got := m.renderBases("C"); got != "C" {
+		t.Errorf("expected C unstyled, got %q", got)
+	}
+}
@@ -0,0 +1,297 @@
+// This file defines the file picker, which opens files into new tabs, and
+// its list of recently opened files.
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/session"
+)
+
+// OpenFunc opens a file into a viewer, for a new tab.
+type OpenFunc func(spec adapter.OpenSpec) (Model, error)
+
+// fileItem is an entry of the file picker: a directory, or a file in a
+// supported format.
+type fileItem struct {
+	name  string // Name shown in the list
+	path  string
+	dir   bool
+	size  int64
+	index string // Index file found next to the file, if any
+}
+
+// Title is the name, with a slash for directories.
+func (i fileItem) Title() string {
+	if i.dir {
+		return i.name + "/"
+	}
+	return i.name
+}
+
+// Description shows the size of a file and whether it has an index.
+func (i fileItem) Description() string {
+	if i.dir {
+		return "directory"
+	}
+	if i.index == "" {
+		return formatSize(i.size) + "  no index"
+	}
+	return formatSize(i.size) + "  indexed (" + strings.TrimPrefix(i.index, i.path) + ")"
+}
+
+// FilterValue is the string the list will filter against.
+func (i fileItem) FilterValue() string { return i.name }
+
+// dirReadMsg carries the entries of a directory read by the picker.
+type dirReadMsg struct {
+	dir   string
+	items []list.Item
+	err   error
+}
+
+// recentReadMsg carries the recent files list.
+type recentReadMsg struct {
+	items []list.Item
+	err   error
+}
+
+// Bindings of the file picker, which are fixed like those of the list filter.
+var (
+	pickerOpen   = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open"))
+	pickerParent = key.NewBinding(key.WithKeys("backspace"), key.WithHelp("backspace", "parent directory"))
+	pickerClose  = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close"))
+)
+
+// picker browses the file system for files the registry can open, next to
+// the recently opened files.
+type picker struct {
+	registry    *adapter.Registry
+	dir         string // Directory being browsed
+	files       list.Model
+	recent      list.Model
+	focusRecent bool // The recent files list has the focus
+	styles      Styles
+	help        help.Model
+	width       int
+	height      int
+}
+
+func newPicker(registry *adapter.Registry, styles Styles) picker {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	return picker{
+		registry: registry,
+		dir:      dir,
+		files:    newPickerList(styles, dir),
+		recent:   newPickerList(styles, "Recent files"),
+		styles:   styles,
+		help:     newHelp(styles),
+	}
+}
+
+func newPickerList(styles Styles, title string) list.Model {
+	ls := list.New(nil, styles.ListDelegate(), 0, 0)
+	ls.Title = title
+	ls.SetShowHelp(false)
+	ls.KeyMap.Quit.SetEnabled(false)
+	ls.KeyMap.ForceQuit.SetEnabled(false)
+	return ls
+}
+
+// load reads the directory being browsed and the recent files.
+func (p picker) load() tea.Cmd {
+	return tea.Batch(p.readDir(p.dir), p.readRecent())
+}
+
+// readDir lists the subdirectories of a directory and the files the
+// registry can open, directories first. Hidden entries are skipped.
+func (p picker) readDir(dir string) tea.Cmd {
+	registry := p.registry
+	return func() tea.Msg {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return dirReadMsg{dir: dir, err: err}
+		}
+		var dirs, files []list.Item
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			// Stat rather than use the entry, so symlinks are followed.
+			it, ok := statFile(registry, filepath.Join(dir, e.Name()))
+			if !ok {
+				continue
+			}
+			it.name = e.Name()
+			if it.dir {
+				dirs = append(dirs, it)
+			} else {
+				files = append(files, it)
+			}
+		}
+		return dirReadMsg{dir: dir, items: append(dirs, files...)}
+	}
+}
+
+// readRecent loads the recently opened files that can still be opened.
+func (p picker) readRecent() tea.Cmd {
+	registry := p.registry
+	return func() tea.Msg {
+		paths, err := session.Recent()
+		if err != nil {
+			return recentReadMsg{err: err}
+		}
+		var items []list.Item
+		for _, path := range paths {
+			if it, ok := statFile(registry, path); ok && !it.dir {
+				items = append(items, it)
+			}
+		}
+		return recentReadMsg{items: items}
+	}
+}
+
+// statFile describes a directory or a supported file for the picker.
+func statFile(registry *adapter.Registry, path string) (fileItem, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileItem{}, false
+	}
+	if info.IsDir() {
+		return fileItem{name: filepath.Base(path), path: path, dir: true}, true
+	}
+	format, ok := registry.Lookup(path)
+	if !ok {
+		return fileItem{}, false
+	}
+	return fileItem{name: path, path: path, size: info.Size(), index: format.Index(path)}, true
+}
+
+// focused returns the list that has the focus.
+func (p *picker) focused() *list.Model {
+	if p.focusRecent {
+		return &p.recent
+	}
+	return &p.files
+}
+
+// capturesKeys reports whether a list filter is being typed.
+func (p picker) capturesKeys() bool {
+	return p.files.FilterState() == list.Filtering || p.recent.FilterState() == list.Filtering
+}
+
+// update handles a message for the picker. It returns the file to open, if
+// one was chosen.
+func (p *picker) update(msg tea.Msg, keys KeyMap) (tea.Cmd, *fileItem) {
+	switch msg := msg.(type) {
+	case dirReadMsg:
+		if msg.err != nil {
+			return p.files.NewStatusMessage(fmt.Sprintf("Could not read %s: %v", msg.dir, msg.err)), nil
+		}
+		p.dir = msg.dir
+		p.files.Title = msg.dir
+		p.files.ResetFilter()
+		p.files.Select(0)
+		return p.files.SetItems(msg.items), nil
+
+	case recentReadMsg:
+		if msg.err != nil {
+			return p.recent.NewStatusMessage(fmt.Sprintf("Could not read recent files: %v", msg.err)), nil
+		}
+		return p.recent.SetItems(msg.items), nil
+
+	case tea.KeyMsg:
+		if p.capturesKeys() {
+			break
+		}
+		switch {
+		case key.Matches(msg, keys.FocusNext):
+			p.focusRecent = !p.focusRecent
+			return nil, nil
+		case key.Matches(msg, pickerParent):
+			return p.readDir(filepath.Dir(p.dir)), nil
+		case key.Matches(msg, pickerOpen):
+			it, ok := p.focused().SelectedItem().(fileItem)
+			if !ok {
+				return nil, nil
+			}
+			if it.dir {
+				return p.readDir(it.path), nil
+			}
+			return nil, &it
+		}
+	}
+
+	var cmd tea.Cmd
+	l := p.focused()
+	*l, cmd = l.Update(msg)
+	return cmd, nil
+}
+
+// setSize lays out the two lists side by side, the recent files taking a
+// third of the width, above the help line.
+func (p *picker) setSize(width, height int) {
+	p.width, p.height = width, height
+	p.help.Width = width
+	frameH := p.styles.Active.GetHorizontalFrameSize()
+	frameV := p.styles.Active.GetVerticalFrameSize()
+	recentWidth := width / 3
+	p.recent.SetSize(recentWidth-frameH, height-footerHeight-frameV)
+	p.files.SetSize(width-recentWidth-frameH, height-footerHeight-frameV)
+}
+
+// view renders the picker with its help line.
+func (p picker) view() string {
+	recentStyle, filesStyle := p.styles.Inactive, p.styles.Active
+	if p.focusRecent {
+		recentStyle, filesStyle = filesStyle, recentStyle
+	}
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		recentStyle.Width(p.recent.Width()+recentStyle.GetHorizontalPadding()).Render(p.recent.View()),
+		filesStyle.Width(p.files.Width()+filesStyle.GetHorizontalPadding()).Render(p.files.View()),
+	)
+	bindings := []key.Binding{pickerOpen, pickerParent, p.files.KeyMap.Filter, pickerClose}
+	footer := lipgloss.NewStyle().MaxWidth(p.width).Render(p.help.ShortHelpView(bindings))
+	return lipgloss.JoinVertical(lipgloss.Left, panes, footer)
+}
+
+// isOpen reports whether a file is already open, returning its tab.
+func isOpen(files []adapter.OpenSpec, path string) (int, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	i := slices.IndexFunc(files, func(spec adapter.OpenSpec) bool {
+		p, err := filepath.Abs(spec.Path)
+		return err == nil && p == abs
+	})
+	return i, i >= 0
+}
+
+// formatSize formats a file size with binary units, e.g. "3.1 GiB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,230 @@
+// This file defines the visual selection of the sequence viewer and copying
+// the selection to the system clipboard.
+
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/bed"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+)
+
+// copyFormat is the text format of a copied selection.
+type copyFormat int
+
+const (
+	copyRaw copyFormat = iota
+	copyFASTA
+	copyRevComp
+)
+
+func (f copyFormat) String() string {
+	switch f {
+	case copyFASTA:
+		return "FASTA"
+	case copyRevComp:
+		return "reverse complement"
+	default:
+		return "sequence"
+	}
+}
+
+// copiedMsg reports the result of copying a selection: err if the OSC 52
+// sequence could not be sent, and localErr if the local clipboard could not
+// be set, or errNoClipboard if there is none.
+type copiedMsg struct {
+	bases    int64
+	format   copyFormat
+	err      error
+	localErr error
+}
+
+// errNoClipboard reports that the system has no local clipboard, as over
+// SSH and on headless systems, where OSC 52 does the work.
+var errNoClipboard = errors.New("no local clipboard")
+
+// highlight is how a column of the viewer is drawn.
+type highlight int
+
+const (
+	highlightNone highlight = iota
+	highlightSelected
+	highlightCursor
+)
+
+// selection returns the selected offsets into the current slice, inclusive.
+func (m Model) selection() (lo, hi int64, ok bool) {
+	if !m.selecting {
+		return 0, 0, false
+	}
+	return min(m.anchor, m.cursor), max(m.anchor, m.cursor), true
+}
+
+// toggleSelection starts a selection at the cursor, or cancels the current one.
+func (m *Model) toggleSelection() {
+	m.selecting = !m.selecting
+	m.anchor = m.cursor
+	m.refresh()
+}
+
+// clearSelection cancels the selection, if any.
+func (m *Model) clearSelection() {
+	if m.selecting {
+		m.selecting = false
+		m.refresh()
+	}
+}
+
+// selectionRegion returns the selection as "ref:start-end", 1-based inclusive.
+func (m Model) selectionRegion() string {
+	lo, hi, _ := m.selection()
+	return fmt.Sprintf("%s:%d-%d", m.ref, m.sliceStart+lo+1, m.sliceStart+hi+1)
+}
+
+// copySelection copies the selection in the given format and ends the
+// selection. The text is sent both as an OSC 52 escape sequence, which
+// terminals honor over SSH, and to the local clipboard when there is one.
+// The sequence goes through Stdout, between the frames of the program.
+func (m *Model) copySelection(format copyFormat) tea.Cmd {
+	lo, hi, ok := m.selection()
+	if !ok {
+		return m.list.NewStatusMessage("Nothing selected: press " + m.keys.Visual.Help().Key + " to start a selection")
+	}
+	if format == copyRevComp && m.seqType == fasta.Protein {
+		return m.list.NewStatusMessage("Reverse complement needs a nucleotide sequence")
+	}
+
+	seq := m.currentSlice.Sequence[lo : hi+1]
+	var text string
+	switch format {
+	case copyFASTA:
+		var buf bytes.Buffer
+		fasta.WriteRecord(&buf, m.selectionRegion(), seq, fasta.DefaultLineWidth)
+		text = buf.String()
+	case copyRevComp:
+		text = string(fasta.ReverseComplement(seq))
+	default:
+		text = string(seq)
+	}
+	m.clearSelection()
+
+	bases := hi - lo + 1
+	return func() tea.Msg {
+		msg := copiedMsg{bases: bases, format: format, localErr: errNoClipboard}
+		_, msg.err = newOSC52(text).WriteTo(Stdout)
+		if !clipboard.Unsupported {
+			msg.localErr = clipboard.WriteAll(text)
+		}
+		return msg
+	}
+}
+
+// newOSC52 builds the OSC 52 sequence for text, wrapped for tmux or screen
+// so that the multiplexer passes it through to the terminal.
+func newOSC52(text string) osc52.Sequence {
+	seq := osc52.New(text)
+	term := os.Getenv("TERM")
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "tmux"):
+		seq = seq.Tmux()
+	case strings.HasPrefix(term, "screen"):
+		seq = seq.Screen()
+	}
+	return seq
+}
+
+// highlightAt returns how a column is drawn, for a row starting at the
+// given offset into the current slice.
+func (m Model) highlightAt(rowStart int64, col int) highlight {
+	bin := m.binSize()
+	colStart := rowStart + int64(col)*bin
+	if m.cursor >= colStart && m.cursor < colStart+bin {
+		return highlightCursor
+	}
+	if lo, hi, ok := m.selection(); ok && colStart <= hi && colStart+bin > lo {
+		return highlightSelected
+	}
+	return highlightNone
+}
+
+// renderColumns renders n columns of a row, grouping runs of columns drawn
+// the same way. Plain runs are rendered by plain; the cursor and selected
+// runs take the text from raw and are styled here.
+func (m Model) renderColumns(rowStart int64, n int, plain, raw func(from, to int) string) string {
+	var b strings.Builder
+	for from := 0; from < n; {
+		h := m.highlightAt(rowStart, from)
+		to := from + 1
+		for to < n && m.highlightAt(rowStart, to) == h {
+			to++
+		}
+		switch h {
+		case highlightCursor:
+			b.WriteString(m.styles.Cursor.Render(raw(from, to)))
+		case highlightSelected:
+			b.WriteString(m.styles.Selection.Render(raw(from, to)))
+		default:
+			b.WriteString(plain(from, to))
+		}
+		from = to
+	}
+	return b.String()
+}
+
+// updateSelectionStats computes the stats panel entries for the selection.
+func (m *Model) updateSelectionStats() {
+	lo, hi, ok := m.selection()
+	if !ok {
+		m.selStats = nil
+		return
+	}
+	c := m.summarize(lo, hi-lo+1)
+	m.selStats = map[string]string{
+		"Selection":  m.selectionRegion(),
+		"Length":     fmt.Sprintf("%d bp", c.Bases),
+		"GC Content": fmt.Sprintf("%.2f%%", float64(c.GC)/float64(c.Bases)*100),
+		"N Count":    fmt.Sprintf("%d", c.N),
+		"Masked":     fmt.Sprintf("%.2f%%", float64(c.Masked)/float64(c.Bases)*100),
+	}
+}
+
+// exportSelection writes the selection to a new BED file named after the
+// file, such as genome.selection.bed, as createExport says.
+func (m *Model) exportSelection() tea.Cmd {
+	lo, hi, ok := m.selection()
+	if !ok {
+		return m.list.NewStatusMessage("Nothing selected: press " + m.keys.Visual.Help().Key + " to start a selection")
+	}
+	rec := bed.Record{Chrom: m.ref, Start: m.sliceStart + lo, End: m.sliceStart + hi + 1}
+	source := m.name
+	return func() (msg tea.Msg) {
+		f, path, err := createExport(source, "selection", ".bed")
+		if err != nil {
+			return bedExportedMsg{err: fmt.Errorf("could not create BED file: %w", err)}
+		}
+		defer func() {
+			f.Close()
+			// Leave no partial file behind.
+			if msg.(bedExportedMsg).err != nil {
+				os.Remove(f.Name())
+			}
+		}()
+
+		w := bed.NewWriter(f)
+		if err := w.Write(rec); err != nil {
+			return bedExportedMsg{err: err}
+		}
+		if err := w.Flush(); err != nil {
+			return bedExportedMsg{err: err}
+		}
+		return bedExportedMsg{path: path, count: 1}
+	}
+}
@@ -0,0 +1,306 @@
+// This file defines how the sequence viewer lays out, renders and zooms the
+// current slice. Only the rows visible in the viewport are rendered, so the
+// cost of a frame does not depend on the length of the sequence.
+
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/summary"
+)
+
+// zoomLevels lists the bases drawn per column at each zoom level. Level 0
+// draws individual bases; coarser levels draw per-bin summary tracks.
+var zoomLevels = []int64{1, 10, 100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000}
+
+// sparkBlocks are the glyphs used to draw summary tracks, from low to high.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// binSize returns the number of bases drawn per column at the current zoom.
+func (m Model) binSize() int64 {
+	return zoomLevels[m.zoom]
+}
+
+// rowSpan returns the number of bases covered by one wrapped row.
+func (m Model) rowSpan() int64 {
+	return int64(m.lineWidth) * m.binSize()
+}
+
+// rowCount returns the number of wrapped rows of the current slice.
+func (m Model) rowCount() int64 {
+	span := m.rowSpan()
+	if span <= 0 {
+		return 0
+	}
+	return (int64(len(m.currentSlice.Sequence)) + span - 1) / span
+}
+
+// layoutRows sizes the coordinate margin and the number of columns per row
+// for the current viewport width.
+func (m *Model) layoutRows() {
+	oldSpan := m.rowSpan()
+
+	// 1. Get the style that will be used for the viewport pane.
+	style := m.styles.Inactive
+
+	// 2. Ask the style for its horizontal padding.
+	padding := style.GetHorizontalPadding()
+	// Size the position indicator margin to the largest coordinate
+	// (e.g., "1234567890 "), leaving room for the lane labels.
+	lastCoord := max(m.refLength, m.sliceStart+int64(len(m.currentSlice.Sequence)))
+	m.marginWidth = max(len(strconv.FormatInt(lastCoord, 10)), laneLabelWidth) + 1
+
+	// The space available for the sequence is the viewport's inner width minus our margin.
+	availableWidth := m.viewport.Width - padding
+	m.lineWidth = availableWidth - m.marginWidth
+	// Keep rows a multiple of ten columns so the ruler ticks line up on every row.
+	if m.lineWidth >= rulerTick {
+		m.lineWidth -= m.lineWidth % rulerTick
+	}
+
+	// Keep the same bases at the top when the row width changes.
+	if span := m.rowSpan(); oldSpan > 0 && span > 0 && span != oldSpan {
+		m.topRow = m.topRow * oldSpan / span
+	}
+}
+
+// refresh renders the rows visible from topRow into the viewport.
+// It must be called after anything that changes what the viewer shows.
+func (m *Model) refresh() {
+	seq := m.currentSlice.Sequence
+	if seq == nil {
+		return
+	}
+	m.layoutRows()
+	if m.lineWidth <= 0 || m.viewport.Height <= 0 {
+		m.viewport.SetContent("")
+		return
+	}
+	m.zoom = min(m.zoom, m.maxZoom())
+	if m.pendingTop != nil {
+		m.topRow = *m.pendingTop / m.rowSpan()
+		m.pendingTop = nil
+	}
+	m.topRow = max(0, min(m.topRow, m.rowCount()-1))
+
+	// Collect the lanes to draw under the rows, starting at the first
+	// interval that reaches the top row.
+	var lanes []*lane
+	if m.zoom == 0 {
+		topStart := m.sliceStart + m.topRow*m.rowSpan()
+		for _, name := range m.visibleTracks() {
+			intervals := m.sliceTrack(name)
+			next := sort.Search(len(intervals), func(i int) bool { return intervals[i].End > topStart })
+			if next < len(intervals) {
+				lanes = append(lanes, &lane{label: adapter.TrackLabel(name), intervals: intervals, next: next})
+			}
+		}
+	}
+
+	var lines []string
+	m.lineRows = m.lineRows[:0]
+	m.visibleRows = 0
+	for row := m.topRow; row < m.rowCount() && len(lines) < m.viewport.Height; row++ {
+		var rowLines []string
+		if m.zoom == 0 {
+			rowLines = m.renderBaseRow(row, lanes)
+		} else {
+			rowLines = m.renderSummaryRow(row)
+		}
+		lines = append(lines, rowLines...)
+		for range rowLines {
+			m.lineRows = append(m.lineRows, row)
+		}
+		m.visibleRows++
+	}
+	m.visibleRows = max(1, m.visibleRows)
+	if len(lines) > m.viewport.Height {
+		lines = lines[:m.viewport.Height]
+		m.lineRows = m.lineRows[:m.viewport.Height]
+	}
+	m.updateSelectionStats()
+
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+	m.viewport.GotoTop()
+}
+
+// renderBaseRow renders one row of individual bases and the lanes under it.
+func (m *Model) renderBaseRow(row int64, lanes []*lane) []string {
+	seq := m.currentSlice.Sequence
+	start := row * m.rowSpan()
+	end := min(start+m.rowSpan(), int64(len(seq)))
+
+	lines := []string{m.renderRow(string(seq[start:end]), start)}
+
+	// Draw a lane under the row for every track that covers part of it.
+	for _, l := range lanes {
+		if marks, ok := l.render(m.sliceStart+start, m.sliceStart+end); ok {
+			lines = append(lines, fmt.Sprintf("%-*s", m.marginWidth, l.label)+m.styles.Lane.Render(marks))
+		}
+	}
+	return lines
+}
+
+// renderRow renders one wrapped row starting at an offset into the current
+// slice: the coordinate margin followed by the bases, with the cursor and the
+// selection drawn over them.
+func (m *Model) renderRow(bases string, rowStart int64) string {
+	// The `%-*d` format right-pads the number with spaces to the margin width.
+	margin := fmt.Sprintf("%-*d", m.marginWidth, m.sliceStart+rowStart+1)
+	plain := func(from, to int) string { return m.renderBases(bases[from:to]) }
+	raw := func(from, to int) string {
+		if m.uppercase {
+			return strings.ToUpper(bases[from:to])
+		}
+		return bases[from:to]
+	}
+	return margin + m.renderColumns(rowStart, len(bases), plain, raw)
+}
+
+// renderSummaryRow renders one row of bins: a GC content track, plus N-run
+// and soft-mask density lanes when the row contains any.
+func (m *Model) renderSummaryRow(row int64) []string {
+	n := int64(len(m.currentSlice.Sequence))
+	start := row * m.rowSpan()
+
+	bins := make([]summary.Counts, 0, m.lineWidth)
+	var total summary.Counts
+	for binStart := start; binStart < min(start+m.rowSpan(), n); binStart += m.binSize() {
+		c := m.summarize(binStart, m.binSize())
+		bins = append(bins, c)
+		total.Add(c)
+	}
+
+	// GC content of the called bases; all-N bins are left blank.
+	gc := make([]string, len(bins))
+	for i, c := range bins {
+		gc[i] = " "
+		if c.N < c.Bases {
+			gc[i] = string(sparkBlock(c.GCFraction()))
+		}
+	}
+	lines := []string{fmt.Sprintf("%-*d", m.marginWidth, m.sliceStart+start+1) + m.renderSpark(gc, start)}
+
+	// Density lanes, drawn only where the row has any.
+	if total.N > 0 {
+		lines = append(lines, m.densityLane(adapter.TrackGaps, bins, summary.Counts.NFraction))
+	}
+	if m.showSoftMask && total.Masked > 0 {
+		lines = append(lines, m.densityLane(adapter.TrackSoftMask, bins, summary.Counts.MaskedFraction))
+	}
+	return lines
+}
+
+// densityLane renders a lane whose glyph height follows a per-bin fraction.
+func (m *Model) densityLane(track string, bins []summary.Counts, fraction func(summary.Counts) float64) string {
+	marks := make([]string, len(bins))
+	for i, c := range bins {
+		marks[i] = " "
+		if f := fraction(c); f > 0 {
+			marks[i] = string(sparkBlock(f))
+		}
+	}
+	return fmt.Sprintf("%-*s", m.marginWidth, adapter.TrackLabel(track)) + m.styles.Lane.Render(strings.Join(marks, ""))
+}
+
+// renderSpark joins the glyphs of a summary track, drawing the cursor bin
+// and the selected bins.
+func (m *Model) renderSpark(glyphs []string, rowStart int64) string {
+	join := func(from, to int) string { return strings.Join(glyphs[from:to], "") }
+	return m.renderColumns(rowStart, len(glyphs), join, join)
+}
+
+// sparkBlock maps a fraction in [0, 1] to a block glyph.
+func sparkBlock(f float64) rune {
+	i := int(math.Round(f * float64(len(sparkBlocks)-1)))
+	return sparkBlocks[max(0, min(i, len(sparkBlocks)-1))]
+}
+
+// summarize returns the composition of a bin, from the cached pyramid for
+// large bins and straight from the sequence for small ones.
+func (m *Model) summarize(start, size int64) summary.Counts {
+	seq := m.currentSlice.Sequence
+	if size < summary.BaseBin {
+		return summary.Count(seq[start:min(start+size, int64(len(seq)))])
+	}
+	if m.pyramid == nil {
+		m.pyramid = summary.NewPyramid(seq)
+	}
+	return m.pyramid.Summarize(start, size)
+}
+
+// maxZoom returns the coarsest useful zoom level: the first one at which the
+// whole slice fits on a single row.
+func (m Model) maxZoom() int {
+	n := int64(len(m.currentSlice.Sequence))
+	for level, size := range zoomLevels {
+		if int64(m.lineWidth)*size >= n {
+			return level
+		}
+	}
+	return len(zoomLevels) - 1
+}
+
+// zoomBy changes the zoom level, keeping the cursor in the middle of the view.
+func (m *Model) zoomBy(delta int) {
+	m.zoom = max(0, min(m.zoom+delta, m.maxZoom()))
+	m.centerOnCursor()
+}
+
+// centerOnCursor scrolls so that the cursor row sits in the middle of the view.
+func (m *Model) centerOnCursor() {
+	if m.rowSpan() <= 0 {
+		return
+	}
+	m.topRow = max(0, m.cursor/m.rowSpan()-int64(m.visibleRows)/2)
+	m.refresh()
+}
+
+// scrollRows scrolls the view by a number of rows; the cursor follows,
+// keeping its column. Past the ends of a window of a remote sequence, the
+// window moves along.
+func (m *Model) scrollRows(delta int64) {
+	span := m.rowSpan()
+	if span <= 0 {
+		return
+	}
+	if top := m.topRow + delta; top < 0 || top > m.rowCount()-int64(m.visibleRows) {
+		m.moveWindow(m.sliceStart + m.cursor + delta*span)
+	}
+	m.topRow = max(0, min(m.topRow+delta, m.rowCount()-int64(m.visibleRows)))
+
+	row := m.cursor / span
+	col := m.cursor % span
+	if row < m.topRow {
+		m.cursor = m.topRow*span + col
+	} else if last := m.topRow + int64(m.visibleRows) - 1; row > last {
+		m.cursor = last*span + col
+	}
+	m.cursor = min(m.cursor, int64(len(m.currentSlice.Sequence))-1)
+	m.refresh()
+}
+
+// zoomLabel describes the current zoom, e.g. "1 bp/col" or "10 kb/col".
+func (m Model) zoomLabel() string {
+	return formatBases(m.binSize()) + "/col"
+}
+
+// formatBases formats a base count with a unit, e.g. 500 bp, 10 kb, 1 Mb.
+func formatBases(n int64) string {
+	switch {
+	case n >= 1_000_000_000 && n%1_000_000_000 == 0:
+		return fmt.Sprintf("%d Gb", n/1_000_000_000)
+	case n >= 1_000_000 && n%1_000_000 == 0:
+		return fmt.Sprintf("%d Mb", n/1_000_000)
+	case n >= 1_000 && n%1_000 == 0:
+		return fmt.Sprintf("%d kb", n/1_000)
+	default:
+		return fmt.Sprintf("%d bp", n)
+	}
+}
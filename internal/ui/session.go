@@ -0,0 +1,117 @@
+// This file defines how the viewer state is saved to and restored from a
+// session.
+
+package ui
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/guillechuma/bio-tui/internal/session"
+)
+
+// stackNames names the stack modes in sessions; the automatic one has none.
+var stackNames = map[stackMode]string{
+	stackOn:  "on",
+	stackOff: "off",
+}
+
+// focusNames names the panes in sessions.
+var focusNames = map[focusState]string{
+	focusList:      "list",
+	focusViewport:  "viewport",
+	focusGaps:      "gaps",
+	focusBookmarks: "bookmarks",
+}
+
+// Session returns the state of the viewer, to be saved in a session.
+func (m Model) Session() session.View {
+	v := session.View{
+		Ref:            m.ref,
+		Cursor:         m.sliceStart + m.cursor,
+		Top:            m.sliceStart + m.topRow*m.rowSpan(),
+		Zoom:           m.binSize(),
+		Focus:          focusNames[m.focus],
+		ShowGaps:       m.showGaps,
+		ShowBookmarks:  m.showBookmarks,
+		SoftMaskLane:   m.showSoftMask,
+		Uppercase:      m.uppercase,
+		Palette:        m.ntPalettes[m.ntPalette].Name,
+		ProteinPalette: m.aaPalettes[m.aaPalette].Name,
+		HideList:       !m.showList,
+		HideStats:      !m.showStats,
+		ListWidth:      m.listWidth,
+		StatsHeight:    m.statsHeight,
+		Stacked:        stackNames[m.stack],
+	}
+	if m.list.FilterState() == list.FilterApplied {
+		v.Filter = m.list.FilterValue()
+	}
+	if m.selecting {
+		anchor := m.sliceStart + m.anchor
+		v.Anchor = &anchor
+	}
+	return v
+}
+
+// Restore puts the viewer back in a saved state. Anything that no longer
+// applies, such as a sequence missing from the file, is skipped.
+func (m *Model) Restore(v session.View) {
+	// 1. Display settings and panes.
+	m.showGaps = v.ShowGaps && m.keys.ToggleGaps.Enabled()
+	m.showBookmarks = v.ShowBookmarks
+	m.showSoftMask = v.SoftMaskLane
+	m.uppercase = v.Uppercase
+	if i := slices.IndexFunc(m.ntPalettes, func(p Palette) bool { return p.Name == v.Palette }); i >= 0 && hasColor() {
+		m.ntPalette = i
+	}
+	if i := slices.IndexFunc(m.aaPalettes, func(p Palette) bool { return p.Name == v.ProteinPalette }); i >= 0 && hasColor() {
+		m.aaPalette = i
+	}
+	m.showList = !v.HideList
+	m.showStats = !v.HideStats
+	m.listWidth = max(0, v.ListWidth)
+	if v.StatsHeight > 0 {
+		m.statsHeight = max(minStatsHeight, v.StatsHeight)
+	}
+	m.stack = stackAuto
+	for stack, name := range stackNames {
+		if name == v.Stacked {
+			m.stack = stack
+		}
+	}
+	for focus, name := range focusNames {
+		if name == v.Focus {
+			m.focus = focus
+		}
+	}
+	if (m.focus == focusGaps && !m.showGaps) || (m.focus == focusBookmarks && !m.showBookmarks) || (m.focus == focusList && !m.showList) {
+		m.focus = focusViewport
+	}
+
+	// 2. The search query, then the sequence among the matches.
+	if v.Filter != "" {
+		m.list.SetFilterText(v.Filter)
+	}
+	index := slices.IndexFunc(m.list.VisibleItems(), func(it list.Item) bool { return it.(item).symbol.Name == v.Ref })
+	if index < 0 {
+		return
+	}
+	m.list.Select(index)
+	m.updateViewportContent()
+
+	// 3. Position, zoom and selection. The top row depends on the terminal
+	// width, so it is computed once the rows are laid out.
+	if i := slices.Index(zoomLevels, v.Zoom); i >= 0 {
+		m.zoom = i
+	}
+	m.moveWindow(v.Cursor)
+	m.cursor = max(0, min(v.Cursor-m.sliceStart, int64(len(m.currentSlice.Sequence))-1))
+	top := v.Top - m.sliceStart
+	m.pendingTop = &top
+	if v.Anchor != nil {
+		m.anchor = max(0, min(*v.Anchor-m.sliceStart, int64(len(m.currentSlice.Sequence))-1))
+		m.selecting = true
+	}
+	m.refresh()
+}
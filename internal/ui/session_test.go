@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bookmark"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+	"github.com/guillechuma/bio-tui/internal/session"
+)
+
+// newSessionModel opens a FASTA file with two sequences in a sized viewer.
+func newSessionModel(t *testing.T, path string) Model {
+	t.Helper()
+	reader := fasta.Format.New()
+	if err := reader.Open(adapter.OpenSpec{Path: path}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { reader.Close() })
+	symbols, _ := reader.ListSymbols()
+	m := NewModel(symbols, reader, config.Default(), bookmark.NewMemoryStore())
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	return next.(Model)
+}
+
+func TestSession_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "genome.fa")
+	content := ">chr1\n" + strings.Repeat("ACGTACGTAC", 50) + "\n>chr2\n" + strings.Repeat("GGCCAATT", 500) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Leave the viewer far from its defaults.
+	m := newSessionModel(t, path)
+	m.list.Select(1)
+	m.updateViewportContent()
+	m.zoomBy(1)
+	m.setCursor(1234)
+	m.toggleSelection()
+	m.setCursor(1300)
+	m.showBookmarks = true
+	m.uppercase = true
+	m.toggleList()
+	m.listWidth = 30
+	m.resizeStats(4)
+	m.toggleStacked()
+	m.focus = focusBookmarks
+	saved := m.Session()
+
+	file := filepath.Join(dir, "session.json")
+	tab := session.Tab{File: session.FromSpec(adapter.OpenSpec{Path: path}), View: saved}
+	if err := session.Save(file, &session.Session{Tabs: []session.Tab{tab}}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := session.Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := s.Find(path)
+	if found == nil {
+		t.Fatal("expected the session to have the file")
+	}
+
+	restored := newSessionModel(t, path)
+	restored.Restore(found.View)
+	if got := restored.Session(); !reflect.DeepEqual(got, saved) {
+		t.Errorf("expected the restored viewer to save\n%+v\ngot\n%+v", saved, got)
+	}
+	if !saved.HideList || saved.ListWidth != 30 || saved.Stacked != "on" || saved.StatsHeight == config.Default().Layout.StatsHeight {
+		t.Errorf("expected the pane layout in the session, got %+v", saved)
+	}
+}
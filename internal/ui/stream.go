@@ -0,0 +1,67 @@
+// This file defines how a viewer follows a file whose records are still
+// arriving, such as records piped to stdin: its list grows as they are read.
+
+package ui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// streamRefresh is the least time between two updates of the list of a
+// stream, so that a fast stream is not listed again for every record.
+const streamRefresh = 250 * time.Millisecond
+
+// streamMsg reports that records were added to a stream, or that it ended.
+type streamMsg struct {
+	done bool
+}
+
+// symbolItems returns the list items of the sequences.
+func symbolItems(symbols []adapter.Symbol) []list.Item {
+	items := make([]list.Item, len(symbols))
+	for i, sym := range symbols {
+		items[i] = item{symbol: sym}
+	}
+	return items
+}
+
+// followStream returns a command that waits for more records of the file,
+// or nil when its adapter is not reading a stream.
+func (m Model) followStream() tea.Cmd {
+	s, ok := m.adapter.(adapter.Stream)
+	if !ok {
+		return nil
+	}
+	changes := s.Changes()
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return streamMsg{done: true}
+		}
+		time.Sleep(streamRefresh) // Let more records come in
+		return streamMsg{}
+	}
+}
+
+// updateStream lists the records read so far and waits for more. Once the
+// stream has ended, it reports how many records were read, or why reading
+// stopped early.
+func (m *Model) updateStream(msg streamMsg) tea.Cmd {
+	symbols, err := m.adapter.ListSymbols()
+	if err != nil {
+		return notify(adapter.LevelError, "Could not list sequences: %v", err)
+	}
+	cmds := []tea.Cmd{m.list.SetItems(symbolItems(symbols))}
+	switch {
+	case !msg.done:
+		cmds = append(cmds, m.followStream())
+	case m.adapter.(adapter.Stream).Err() != nil:
+		cmds = append(cmds, notify(adapter.LevelError, "Stopped reading after %d sequences: %v", len(symbols), m.adapter.(adapter.Stream).Err()))
+	default:
+		cmds = append(cmds, notify(adapter.LevelInfo, "Read all %d sequences", len(symbols)))
+	}
+	return tea.Batch(cmds...)
+}
@@ -1,30 +1,85 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guillechuma/bio-tui/internal/config"
+)
 
 // Struct to hold all styles
 type Styles struct {
 	Base,
 	Active,
-	Inactive lipgloss.Style
+	Inactive,
+	Highlight,
+	Cursor,
+	Selection,
+	Ruler,
+	Status,
+	Lane,
+	SoftMask,
+	Error lipgloss.Style
 }
 
-// NewStyles creates a new Styles struct with default settings.
-func NewStyles() Styles {
+// NewStyles creates a new Styles struct from the colors of a theme.
+func NewStyles(theme config.Theme) Styles {
 	s := Styles{}
 	// A base style for both panes
 	s.Base = lipgloss.NewStyle().
 		Padding(1, 2).
-		BorderForeground(lipgloss.Color("240")) // A nice dim gray
+		BorderForeground(lipgloss.Color(theme.Border))
 
 	// Style for the active pane
 	s.Active = s.Base.Copy().
 		Border(lipgloss.DoubleBorder(), true).
-		BorderForeground(lipgloss.Color("205")) // A vibrant pink/magenta
+		BorderForeground(lipgloss.Color(theme.ActiveBorder))
 
 	// Style for the inactive pane
 	s.Inactive = s.Base.Copy().
 		Border(lipgloss.NormalBorder(), true)
 
+	// Style for selected items
+	s.Highlight = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Highlight))
+
+	// Style for the base under the cursor
+	s.Cursor = lipgloss.NewStyle().
+		Reverse(true).
+		Bold(true)
+
+	// Style for selected bases
+	s.Selection = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Highlight)).
+		Reverse(true)
+
+	// Style for the ruler above the sequence
+	s.Ruler = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Border))
+
+	// Style for the status bar below the sequence
+	s.Status = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Highlight))
+
+	// Style for track lanes drawn under the sequence
+	s.Lane = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Lane))
+
+	// Style for soft-masked (lowercase) bases
+	s.SoftMask = lipgloss.NewStyle().
+		Faint(true)
+
+	// Style for errors, in a red that no theme color is expected to clash with
+	s.Error = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("9"))
+
 	return s
 }
+
+// ListDelegate returns a list delegate whose selected item uses the highlight color.
+func (s Styles) ListDelegate() list.DefaultDelegate {
+	d := list.NewDefaultDelegate()
+	color := s.Highlight.GetForeground()
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(color).BorderForeground(color)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(color).BorderForeground(color)
+	return d
+}
@@ -0,0 +1,469 @@
+// This file defines the tabs that hold one viewer per open file, the
+// go-to-region prompt shared by all of them and the file picker that opens
+// new ones. Files still being opened are in loading.go.
+
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/guillechuma/bio-tui/internal/remote"
+)
+
+// tabMsg carries a message produced by the commands of one tab back to it,
+// so that async results land in the tab that asked for them.
+type tabMsg struct {
+	index int
+	msg   tea.Msg
+}
+
+// Tabs is the top-level model: a viewer per open file, of which one is shown.
+type Tabs struct {
+	tabs      []Model
+	files     []adapter.OpenSpec // File open in each tab
+	jobs      []*job             // Opening of the file of each tab; nil once open
+	nextJob   int
+	err       error // Why the last tab could not be opened
+	active    int
+	sync      bool // Go-to-region applies to every tab with the sequence
+	prompting bool // The go-to-region prompt is open
+	input     textinput.Model
+	open      OpenFunc
+	picker    picker
+	picking   bool // The file picker is shown
+	notifier  *Notifier
+	notices   []notice // Message log, oldest first
+	toasts    []toast
+	nextToast int
+	showLog   bool // The message log is shown
+	log       viewport.Model
+	styles    Styles
+	keys      KeyMap
+	width     int
+	height    int
+}
+
+// NewTabs creates the tabs, to which files are added with Add and Load. The
+// picker offers the files of the registry, and files are opened with open.
+// Notices posted to notifier are shown as they come.
+func NewTabs(cfg config.Config, registry *adapter.Registry, open OpenFunc, notifier *Notifier) Tabs {
+	styles := NewStyles(cfg.Theme)
+	ti := textinput.New()
+	ti.Prompt = "Go to: "
+	ti.PromptStyle = styles.Highlight
+	ti.Placeholder = "chr1:1,000-2,000"
+	ti.CharLimit = 200
+	return Tabs{
+		input:    ti,
+		open:     open,
+		picker:   newPicker(registry, styles),
+		notifier: notifier,
+		log:      viewport.New(0, 0),
+		styles:   styles,
+		keys:     NewKeyMap(cfg.Keys),
+	}
+}
+
+// Models returns the viewer of every tab whose file is open, in tab order.
+func (t Tabs) Models() []Model {
+	var models []Model
+	for i, m := range t.tabs {
+		if !t.loading(i) {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// Files returns the file of every tab whose file is open, in tab order.
+func (t Tabs) Files() []adapter.OpenSpec {
+	var files []adapter.OpenSpec
+	for i, spec := range t.files {
+		if !t.loading(i) {
+			files = append(files, spec)
+		}
+	}
+	return files
+}
+
+// Active returns the index of the tab being shown.
+func (t Tabs) Active() int { return t.active }
+
+// Sync reports whether go-to-region applies to every tab.
+func (t Tabs) Sync() bool { return t.sync }
+
+// Restore shows a tab and sets the sync mode, as saved in a session.
+func (t *Tabs) Restore(active int, sync bool) {
+	if active >= 0 && active < len(t.tabs) {
+		t.active = active
+	}
+	t.sync = sync
+}
+
+// Init is the first command that's run when the program starts: it starts
+// opening the files added with Load, and the viewers of the others.
+func (t Tabs) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	if t.notifier != nil {
+		cmds = append(cmds, t.notifier.wait())
+	}
+	for i, j := range t.jobs {
+		if j != nil {
+			cmds = append(cmds, j.start(t.open))
+		} else {
+			cmds = append(cmds, wrapCmd(i, t.tabs[i].Init()))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// Update handles the tab and prompt keys, and routes everything else to
+// the active tab.
+func (t Tabs) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tabMsg:
+		if n, ok := msg.msg.(noticeMsg); ok {
+			return t, t.addNotice(notice(n))
+		}
+		return t, t.updateTab(msg.index, msg.msg)
+
+	case noticeMsg:
+		return t, t.addNotice(notice(msg))
+
+	case noticesMsg:
+		cmds := []tea.Cmd{t.notifier.wait()}
+		for _, n := range msg {
+			cmds = append(cmds, t.addNotice(n))
+		}
+		return t, tea.Batch(cmds...)
+
+	case toastExpiredMsg:
+		t.expireToast(msg.id)
+		return t, nil
+
+	case tea.WindowSizeMsg:
+		t.width = msg.Width
+		t.height = msg.Height
+		return t, t.resize()
+
+	case progressMsg:
+		return t, t.updateProgress(msg)
+
+	case fileOpenedMsg:
+		// The tab bar may just have appeared or gone, so every tab is laid
+		// out again.
+		return t, t.finishJob(msg)
+
+	case tea.MouseMsg:
+		if t.showLog {
+			var cmd tea.Cmd
+			t.log, cmd = t.log.Update(msg)
+			return t, cmd
+		}
+		if t.prompting || t.picking {
+			return t, nil
+		}
+		if msg.Y < t.barHeight() {
+			if i := t.tabAt(msg.X); i >= 0 && isLeftPress(msg) {
+				t.active = i
+			}
+			return t, nil
+		}
+		msg.Y -= t.barHeight()
+		return t, t.updateTab(t.active, msg)
+
+	case tea.KeyMsg:
+		if t.prompting {
+			return t, t.updatePrompt(msg)
+		}
+		if t.picking {
+			return t, t.updatePicker(msg)
+		}
+		if t.showLog {
+			return t, t.updateLogPane(msg)
+		}
+		if len(t.tabs) == 0 {
+			return t, nil // The program is ending
+		}
+		loading := t.loading(t.active)
+		if loading || !t.tabs[t.active].capturesKeys() {
+			switch {
+			case key.Matches(msg, t.keys.MessageLog):
+				t.showLog = true
+				return t, nil
+			case key.Matches(msg, t.keys.OpenFile):
+				t.picking = true
+				return t, t.picker.load()
+			case key.Matches(msg, t.keys.NextTab):
+				t.active = (t.active + 1) % len(t.tabs)
+				return t, nil
+			case key.Matches(msg, t.keys.PrevTab):
+				t.active = (t.active + len(t.tabs) - 1) % len(t.tabs)
+				return t, nil
+			case loading:
+				return t, t.updateJob(msg)
+			case key.Matches(msg, t.keys.GotoRegion):
+				t.prompting = true
+				t.input.SetValue("")
+				return t, t.input.Focus()
+			case key.Matches(msg, t.keys.ToggleSync):
+				t.sync = !t.sync
+				status := "Go to region: this tab only"
+				if t.sync {
+					status = "Go to region: all tabs"
+				}
+				return t, t.status(status)
+			}
+		}
+		return t, t.updateTab(t.active, msg)
+	}
+
+	// Anything else, like the cursor blink of the prompt, goes to whoever
+	// has the keyboard.
+	switch msg.(type) {
+	case dirReadMsg, recentReadMsg:
+		return t, t.updatePicker(msg)
+	}
+	if t.picking {
+		return t, t.updatePicker(msg)
+	}
+	if t.prompting {
+		var cmd tea.Cmd
+		t.input, cmd = t.input.Update(msg)
+		return t, cmd
+	}
+	return t, t.updateTab(t.active, msg)
+}
+
+// resize lays out the picker and every tab for the terminal size, so that
+// switching tabs does not wait for a resize.
+func (t *Tabs) resize() tea.Cmd {
+	t.input.Width = t.width - lipgloss.Width(t.input.Prompt) - 1
+	t.picker.setSize(t.width, t.height-t.barHeight())
+	t.sizeLog(t.width, t.height-t.barHeight())
+	size := tea.WindowSizeMsg{Width: t.width, Height: t.height - t.barHeight()}
+	cmds := make([]tea.Cmd, len(t.tabs))
+	for i := range t.tabs {
+		cmds[i] = t.updateTab(i, size)
+	}
+	return tea.Batch(cmds...)
+}
+
+// updatePicker handles a message while the file picker is shown. A chosen
+// file that is already open is switched to rather than opened again.
+func (t *Tabs) updatePicker(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !t.picker.capturesKeys() {
+		switch {
+		case key.Matches(keyMsg, pickerClose), key.Matches(keyMsg, t.keys.OpenFile):
+			t.picking = false
+			return nil
+		case key.Matches(keyMsg, t.keys.Quit):
+			return t.updateTab(t.active, msg)
+		}
+	}
+
+	cmd, chosen := t.picker.update(msg, t.keys)
+	if chosen == nil {
+		return cmd
+	}
+	t.picking = false
+	if i, ok := isOpen(t.files, chosen.path); ok {
+		t.active = i
+		return nil
+	}
+	return t.load(adapter.OpenSpec{Path: chosen.path})
+}
+
+// updateTab passes a message to a tab, tagging the commands it returns.
+// Tabs whose file is still being opened have no viewer to pass it to.
+func (t *Tabs) updateTab(i int, msg tea.Msg) tea.Cmd {
+	if i < 0 || i >= len(t.tabs) || t.loading(i) {
+		return nil
+	}
+	next, cmd := t.tabs[i].Update(msg)
+	t.tabs[i] = next.(Model)
+	return wrapCmd(i, cmd)
+}
+
+// wrapCmd tags the messages of a tab's command with the tab index. Batches
+// are unpacked so that each command in them is tagged, and quitting is left
+// for the program to see.
+func wrapCmd(i int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.QuitMsg:
+			return msg
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, len(msg))
+			for j, c := range msg {
+				cmds[j] = wrapCmd(i, c)
+			}
+			return cmds
+		default:
+			return tabMsg{index: i, msg: msg}
+		}
+	}
+}
+
+// updatePrompt handles a key while the go-to-region prompt is open.
+func (t *Tabs) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, editAccept):
+		t.prompting = false
+		t.input.Blur()
+		return t.gotoRegion(t.input.Value())
+	case key.Matches(msg, editCancel):
+		t.prompting = false
+		t.input.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	t.input, cmd = t.input.Update(msg)
+	return cmd
+}
+
+// gotoRegion goes to a region in the active tab and, with sync on, in every
+// other tab that has its sequence.
+func (t *Tabs) gotoRegion(text string) tea.Cmd {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	region, err := adapter.ParseRegion(text)
+	if err != nil {
+		return t.status(fmt.Sprintf("Go to: %v", err))
+	}
+
+	cmds := []tea.Cmd{wrapCmd(t.active, t.tabs[t.active].gotoRegion(region))}
+	if t.sync {
+		for i := range t.tabs {
+			if i != t.active && !t.loading(i) && t.tabs[i].refIndex(region.Ref) >= 0 {
+				cmds = append(cmds, wrapCmd(i, t.tabs[i].gotoRegion(region)))
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// status shows a message in the list of the active tab, if its file is open.
+func (t *Tabs) status(text string) tea.Cmd {
+	if t.loading(t.active) {
+		return nil
+	}
+	return wrapCmd(t.active, t.tabs[t.active].list.NewStatusMessage(text))
+}
+
+// barHeight returns the height of the tab bar, which is only shown when
+// there is more than one tab.
+func (t Tabs) barHeight() int {
+	if len(t.tabs) > 1 {
+		return 1
+	}
+	return 0
+}
+
+// tabLabel returns the label of a tab in the bar, with the progress of its
+// file while it is being opened.
+func (t Tabs) tabLabel(i int) string {
+	label := fmt.Sprintf(" %d:%s ", i+1, fileName(t.files[i].Path))
+	if t.loading(i) {
+		if f := t.jobs[i].progress.Fraction(); f >= 0 {
+			label += fmt.Sprintf("%.0f%% ", f*100)
+		} else {
+			label += "… "
+		}
+	}
+	return label
+}
+
+// fileName returns the name of a file shown in its tab: its base name, the
+// last part of the path of a URL, or "stdin".
+func fileName(path string) string {
+	switch {
+	case path == "-":
+		return "stdin"
+	case remote.IsURL(path):
+		return remote.Base(path)
+	}
+	return filepath.Base(path)
+}
+
+// tabAt returns the tab whose label is drawn at a column of the bar, or -1.
+func (t Tabs) tabAt(x int) int {
+	for i := range t.tabs {
+		w := lipgloss.Width(t.tabLabel(i))
+		if x < w {
+			return i
+		}
+		x -= w
+	}
+	return -1
+}
+
+// renderTabBar renders the tab labels, the active one highlighted, and the
+// sync mode on the right.
+func (t Tabs) renderTabBar() string {
+	var b strings.Builder
+	for i := range t.tabs {
+		label := t.tabLabel(i)
+		if i == t.active {
+			label = t.styles.Selection.Render(label)
+		}
+		b.WriteString(label)
+	}
+	bar := b.String()
+	if t.sync {
+		sync := t.styles.Status.Render("sync")
+		bar += strings.Repeat(" ", max(1, t.width-lipgloss.Width(bar)-lipgloss.Width(sync))) + sync
+	}
+	return lipgloss.NewStyle().MaxWidth(t.width).Render(bar)
+}
+
+// View renders the tab bar above the active tab, with the prompt in place
+// of its help footer while it is open.
+func (t Tabs) View() string {
+	// The last tab closes when its file cannot be opened, just before the
+	// program ends.
+	if len(t.tabs) == 0 {
+		return ""
+	}
+	m := t.tabs[t.active]
+	if m.quitting || t.width == 0 {
+		return m.View()
+	}
+
+	var view string
+	switch {
+	case t.picking:
+		view = t.picker.view()
+	case t.showLog:
+		view = t.renderLog()
+	case t.loading(t.active):
+		view = t.renderJob(t.jobs[t.active])
+	default:
+		view = m.View()
+	}
+	view = t.overlayToasts(view)
+	if t.prompting {
+		lines := strings.Split(view, "\n")
+		lines[len(lines)-1] = lipgloss.NewStyle().MaxWidth(t.width).Render(t.input.View())
+		view = strings.Join(lines, "\n")
+	}
+	if t.barHeight() == 0 {
+		return view
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, t.renderTabBar(), view)
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/config"
+)
+
+func TestTabs_CancelLastLoadingTab(t *testing.T) {
+	open := func(spec adapter.OpenSpec) (Model, error) {
+		<-spec.Context.Done()
+		return Model{}, spec.Context.Err()
+	}
+	tabs := NewTabs(config.Default(), adapter.NewRegistry(), open, nil)
+	tabs.Load(adapter.OpenSpec{Path: "big.fa"})
+	next, _ := tabs.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	tabs = next.(Tabs)
+
+	msg := tabs.jobs[0].run(open)()
+	next, cmd := tabs.Update(msg)
+	if cmd == nil {
+		t.Fatal("expected the program to end")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("expected the program to end, got %T", cmd())
+	}
+	// The program renders once more, and may still get keys, before it ends.
+	if view := next.View(); view != "" {
+		t.Errorf("expected an empty view, got %q", view)
+	}
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyCtrlN}, {Type: tea.KeyCtrlP}, {Type: tea.KeyRunes, Runes: []rune("g")}} {
+		next, _ = next.Update(key)
+	}
+	if err := next.(Tabs).Err(); err != nil {
+		t.Errorf("expected no error for a cancelled file, got %v", err)
+	}
+}
+
+func TestTabs_FailedLastTab(t *testing.T) {
+	open := func(adapter.OpenSpec) (Model, error) { return Model{}, errors.New("404 Not Found") }
+	tabs := NewTabs(config.Default(), adapter.NewRegistry(), open, nil)
+	tabs.Load(adapter.OpenSpec{Path: "https://example.org/missing.fa"})
+
+	next, _ := tabs.Update(tabs.jobs[0].run(open)())
+	if view := next.View(); view != "" {
+		t.Errorf("expected an empty view, got %q", view)
+	}
+	if err := next.(Tabs).Err(); err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("expected the error of the file, got %v", err)
+	}
+}
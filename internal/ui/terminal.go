@@ -0,0 +1,36 @@
+// This file defines the terminal output that the program renders to and
+// that the viewer writes its own escape sequences to.
+
+package ui
+
+import (
+	"os"
+	"sync"
+)
+
+// Stdout is the output to give the program with tea.WithOutput. Frames and
+// the OSC 52 sequences of clipboard copies are both written to it, one
+// write at a time, so that a sequence never lands inside a frame.
+var Stdout = &Terminal{File: os.Stdout}
+
+// Terminal is a terminal file whose writes do not interleave. It is still
+// an *os.File to the program, which sizes the terminal from it.
+type Terminal struct {
+	*os.File
+	mu sync.Mutex
+}
+
+// Write writes p to the terminal once no other write is in progress.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+// WriteString is Write for a string, which the program uses for single
+// escape sequences.
+func (t *Terminal) WriteString(s string) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.WriteString(s)
+}
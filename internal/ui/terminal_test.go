@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bookmark"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+)
+
+func TestCopySelection_WritesToStdout(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "genome.fa")
+	if err := os.WriteFile(path, []byte(">chr1\nACGTACGTAC\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reader := fasta.Format.New()
+	if err := reader.Open(adapter.OpenSpec{Path: path}); err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	symbols, _ := reader.ListSymbols()
+	m := NewModel(symbols, reader, config.Default(), bookmark.NewMemoryStore())
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = next.(Model)
+	m.updateViewportContent()
+
+	out, err := os.Create(filepath.Join(dir, "terminal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout := Stdout
+	Stdout = &Terminal{File: out}
+	defer func() { Stdout = stdout }()
+	// Leave the clipboard of whoever runs the test alone.
+	unsupported := clipboard.Unsupported
+	clipboard.Unsupported = true
+	defer func() { clipboard.Unsupported = unsupported }()
+
+	m.cursor = 2
+	m.toggleSelection()
+	m.cursor = 5
+	msg := m.copySelection(copyRaw)().(copiedMsg)
+	if msg.err != nil || msg.localErr != errNoClipboard || msg.bases != 4 {
+		t.Fatalf("expected 4 bases copied, got %d, %v, %v", msg.bases, msg.err, msg.localErr)
+	}
+	got, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := newOSC52("GTAC").String(); string(got) != want {
+		t.Errorf("expected %q on the terminal, got %q", want, got)
+	}
+}
@@ -0,0 +1,73 @@
+// This file defines the window of a remote sequence held by the viewer:
+// bases are fetched over the network a window at a time as they are
+// viewed, rather than the whole sequence when it is selected.
+
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+)
+
+// remoteWindow is the number of bases of a remote sequence held at once. A
+// window is read in a single range request, and bounds the coarsest zoom.
+const remoteWindow = 1 << 20
+
+// symbolRegion returns the region read when a sequence is selected: all of
+// it, or its first window for remote files.
+func (m Model) symbolRegion(sym adapter.Symbol) adapter.Region {
+	region := adapter.Region{Ref: sym.Name, Start: 1, End: sym.Length}
+	if m.window > 0 {
+		region.End = min(sym.Length, m.window)
+	}
+	return region
+}
+
+// windowStats adds the window to the stats of a slice, since they cover
+// it rather than the whole sequence.
+func (m Model) windowStats(slice adapter.Slice, region adapter.Region) {
+	if m.window > 0 && region.End-region.Start+1 < m.refLength && slice.Stats != nil {
+		slice.Stats["Window"] = region.String()
+	}
+}
+
+// moveWindow reads the window of a remote sequence around a 0-based
+// position, clamped to the sequence, if it is not in the current one. The
+// cursor, the selection anchor and the top row keep the bases they point
+// at, or are clamped to the new window. Whole sequences never move.
+func (m *Model) moveWindow(pos int64) {
+	n := int64(len(m.currentSlice.Sequence))
+	pos = max(0, min(pos, m.refLength-1))
+	if m.window == 0 || n == 0 || (pos >= m.sliceStart && pos < m.sliceStart+n) {
+		return
+	}
+
+	start := max(0, min(pos-m.window/2, m.refLength-m.window))
+	region := adapter.Region{Ref: m.ref, Start: start + 1, End: min(start+m.window, m.refLength)}
+	slice, err := m.adapter.Region(region)
+	if err != nil {
+		m.windowCmd = tea.Batch(m.windowCmd, notify(adapter.LevelError, "Could not read %s: %v", region, err))
+		return
+	}
+	m.windowStats(slice, region)
+
+	shift := m.sliceStart - start
+	last := int64(len(slice.Sequence)) - 1
+	m.currentSlice = slice
+	m.sliceStart = start
+	m.pyramid = nil
+	m.cursor = max(0, min(m.cursor+shift, last))
+	m.anchor = max(0, min(m.anchor+shift, last))
+	if span := m.rowSpan(); span > 0 {
+		m.topRow = max(0, m.topRow*span+shift) / span
+	}
+	m.windowCmd = tea.Batch(m.windowCmd, m.updateGapList())
+}
+
+// takeWindowCmd returns the commands left by moving the window, such as a
+// notice that it could not be read, once.
+func (m *Model) takeWindowCmd() tea.Cmd {
+	cmd := m.windowCmd
+	m.windowCmd = nil
+	return cmd
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/guillechuma/bio-tui/internal/adapter"
+	"github.com/guillechuma/bio-tui/internal/bookmark"
+	"github.com/guillechuma/bio-tui/internal/config"
+	"github.com/guillechuma/bio-tui/internal/fasta"
+)
+
+// countingWriter counts the bytes of a response.
+type countingWriter struct {
+	http.ResponseWriter
+	n *atomic.Int64
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	w.n.Add(int64(len(p)))
+	return w.ResponseWriter.Write(p)
+}
+
+func TestRemoteWindow(t *testing.T) {
+	// A 3 Mb sequence, 60 bases per line, with its index.
+	const length = 3_000_000
+	dir := t.TempDir()
+	path := filepath.Join(dir, "genome.fa")
+	line := strings.Repeat("ACGTACGTAC", 6) + "\n"
+	content := ">chr1\n" + strings.Repeat(line, length/60)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	local, err := fasta.NewIndexedReader(adapter.OpenSpec{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	local.Close()
+
+	var served atomic.Int64
+	files := http.FileServer(http.Dir(dir))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		files.ServeHTTP(countingWriter{w, &served}, r)
+	}))
+	defer srv.Close()
+
+	reader := fasta.Format.New()
+	if err := reader.Open(adapter.OpenSpec{Path: srv.URL + "/genome.fa"}); err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	symbols, _ := reader.ListSymbols()
+	m := NewModel(symbols, reader, config.Default(), bookmark.NewMemoryStore())
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = next.(Model)
+	m.updateViewportContent()
+
+	// Selecting the sequence reads its first window only.
+	if n := len(m.currentSlice.Sequence); n != remoteWindow || m.sliceStart != 0 {
+		t.Fatalf("expected the first %d bases, got %d from %d", remoteWindow, n, m.sliceStart)
+	}
+	if got := served.Load(); got > 2*remoteWindow {
+		t.Errorf("expected about one window downloaded, got %d bytes of %d", got, len(content))
+	}
+
+	// Going further moves the window there, keeping the position.
+	m.gotoLocation(bookmark.Location{Ref: "chr1", Pos: 2_500_000})
+	if pos := m.sliceStart + m.cursor; pos != 2_500_000 {
+		t.Errorf("expected the cursor at 2500000, got %d", pos)
+	}
+	if m.sliceStart == 0 || m.sliceStart+int64(len(m.currentSlice.Sequence)) > length {
+		t.Errorf("expected a window inside the sequence around the cursor, got %d bases from %d", len(m.currentSlice.Sequence), m.sliceStart)
+	}
+	if base := m.currentSlice.Sequence[m.cursor]; base != "ACGTACGTAC"[2_500_000%10] {
+		t.Errorf("expected base %c at the cursor, got %c", "ACGTACGTAC"[2_500_000%10], base)
+	}
+	if got := served.Load(); got > 4*remoteWindow {
+		t.Errorf("expected about two windows downloaded, got %d bytes of %d", got, len(content))
+	}
+
+	// Scrolling back past the start of the window moves it back.
+	start := m.sliceStart
+	m.setCursor(-1)
+	if m.sliceStart >= start || m.sliceStart+m.cursor != start-1 {
+		t.Errorf("expected the window to move before %d, got the cursor at %d from %d", start, m.sliceStart+m.cursor, m.sliceStart)
+	}
+}